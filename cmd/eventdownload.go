@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jbronder/geteq/logic"
+)
+
+var (
+	EventDirFlag     string
+	EventProductFlag string
+	EventGlobFlag    string
+)
+
+func init() {
+	singleEventCmd.Flags().StringVar(&EventDirFlag, "dir", "", "download view: directory to save files into (default: the eventid)")
+	singleEventCmd.Flags().StringVar(&EventProductFlag, "product", "", "download view: comma-separated product types to download, e.g. shakemap,dyfi (default: all)")
+	singleEventCmd.Flags().StringVar(&EventGlobFlag, "glob", "*", "download view: only save files whose path or name matches the glob, e.g. '*.xml'")
+}
+
+// runDownload saves the content files of the event's preferred products into
+// a directory, resuming partial downloads from earlier runs.
+func runDownload(id string) error {
+	detail, err := logic.RequestDetail(id)
+	if err != nil {
+		return err
+	}
+
+	var productTypes []string
+	for _, productType := range strings.Split(EventProductFlag, ",") {
+		if productType = strings.TrimSpace(productType); len(productType) > 0 {
+			productTypes = append(productTypes, productType)
+		}
+	}
+
+	files, err := logic.SelectContents(detail, productTypes, EventGlobFlag)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "No product files matched.")
+		return nil
+	}
+
+	dir := EventDirFlag
+	if len(dir) == 0 {
+		dir = detail.Id
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for i, f := range files {
+		dest, err := f.Destination(dir)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s/%s (%d bytes)\n", i+1, len(files), f.ProductType, f.Path, f.Length)
+		downloaded, err := logic.DownloadContent(ctx, f.Content, dest)
+		if err != nil {
+			return err
+		}
+		if downloaded {
+			fmt.Println(dest)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s is already complete\n", dest)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/jbronder/geteq/logic"
+)
+
+var (
+	EventDYFISourceFlag string
+	EventBinWidthFlag   float64
+)
+
+func init() {
+	singleEventCmd.Flags().StringVar(&EventDYFISourceFlag, "dyfi-source", "", "dyfi view: summary to read: {geo, zip} (default: geo when available)")
+	singleEventCmd.Flags().Float64Var(&EventBinWidthFlag, "bin-width", 25, "dyfi view: width of the distance bins in km")
+}
+
+// runDYFI outputs the DYFI intensities of an event: a table aggregated by
+// distance, the cells as CSV for plotting, or both as JSON.
+func runDYFI(id string) error {
+	if FDSNFormatFlag != "table" && FDSNFormatFlag != "json" && FDSNFormatFlag != "csv" {
+		return logic.ErrFlagFormatOption
+	}
+
+	detail, err := logic.RequestDetail(id)
+	if err != nil {
+		return err
+	}
+
+	cells, err := logic.RequestDYFI(detail, EventDYFISourceFlag)
+	if err != nil {
+		return err
+	}
+
+	if FDSNFormatFlag == "csv" {
+		return logic.WriteDYFICSV(os.Stdout, cells)
+	}
+
+	bins, err := logic.BinDYFI(cells, EventBinWidthFlag)
+	if err != nil {
+		return err
+	}
+
+	// RequestDYFI has read the cells from the preferred dyfi product.
+	p, _ := detail.Props.Products.Preferred(logic.PRODUCTDYFI)
+	props := p.DYFIProperties()
+
+	if FDSNFormatFlag == "json" {
+		return json.NewEncoder(os.Stdout).Encode(struct {
+			Responses *int             `json:"responses"`
+			MaxCDI    *float64         `json:"maxCdi"`
+			Bins      []logic.DYFIBin  `json:"bins"`
+			Cells     []logic.DYFICell `json:"cells"`
+		}{props.NumResponses, props.MaxMMI, bins, cells})
+	}
+	logic.StdoutDYFI(props, bins)
+	return nil
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/jbronder/geteq/logic"
+)
+
+// runMechanism outputs the moment tensor or focal mechanism of an event.
+func runMechanism(id string) error {
+	if FDSNFormatFlag != "table" && FDSNFormatFlag != "json" {
+		return logic.ErrFlagFormatOption
+	}
+
+	detail, err := logic.RequestDetail(id)
+	if err != nil {
+		return err
+	}
+
+	m, err := detail.Mechanism()
+	if err != nil {
+		return err
+	}
+
+	if FDSNFormatFlag == "json" {
+		return json.NewEncoder(os.Stdout).Encode(m)
+	}
+	logic.StdoutMechanism(m)
+	return nil
+}
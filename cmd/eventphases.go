@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/jbronder/geteq/logic"
+)
+
+// runPhases outputs the phase arrivals of an event as a table, CSV or JSON.
+func runPhases(id string) error {
+	if FDSNFormatFlag != "table" && FDSNFormatFlag != "json" && FDSNFormatFlag != "csv" {
+		return logic.ErrFlagFormatOption
+	}
+
+	detail, err := logic.RequestDetail(id)
+	if err != nil {
+		return err
+	}
+
+	phases, err := logic.RequestPhases(detail)
+	if err != nil {
+		return err
+	}
+
+	switch FDSNFormatFlag {
+	case "json":
+		return json.NewEncoder(os.Stdout).Encode(phases)
+	case "csv":
+		return logic.WritePhasesCSV(os.Stdout, phases)
+	}
+	logic.StdoutPhases(phases)
+	return nil
+}
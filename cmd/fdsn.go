@@ -1,16 +1,28 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/jbronder/geteq/logic"
+	"github.com/spf13/cobra"
+)
 
 var FDSNDateTimeFlag string
 var FDSNMagFlag string
 var FDSNFormatFlag string
+var FDSNUpdatedFlag string
+var FDSNCatalogFlag string
+var FDSNContributorFlag string
+var FDSNFilterFlags logic.FilterFlags
+var FDSNOutputFlags logic.OutputFlags
 
 func init() {
 	rootCmd.AddCommand(fdsnCmd)
 	fdsnCmd.PersistentFlags().StringVarP(&FDSNMagFlag, "magnitude", "m", "", `magnitude or magnitude range (e.g. low[,high] "2.3,4.5")`)
-	fdsnCmd.PersistentFlags().StringVarP(&FDSNDateTimeFlag, "time", "t", "", `UTC datetime range (e.g. startdate,enddate "2024-09-20,2024-09-21")`)
-	fdsnCmd.PersistentFlags().StringVarP(&FDSNFormatFlag, "output", "o", "table", "output format options: {csv, json, table, text}")
+	fdsnCmd.PersistentFlags().StringVarP(&FDSNDateTimeFlag, "time", "t", "", `datetime range, UTC unless an offset is given (e.g. startdate,enddate "2024-09-20,2024-09-21", open-ended "2024-09-20," or relative "-7d", "-36h", "last 2 weeks")`)
+	fdsnCmd.PersistentFlags().StringVarP(&FDSNFormatFlag, "output", "o", "table", "output format options: {csv, json, kml, kmz, ndjson, quakeml, table, text, xml}")
+	fdsnCmd.PersistentFlags().StringVar(&FDSNUpdatedFlag, "updated-after", "", `only events created or updated after a datetime (e.g. "2024-09-20T12:00:00" or relative "-1d")`)
+	fdsnCmd.PersistentFlags().StringVar(&FDSNCatalogFlag, "catalog", "", "limit to events from a catalog (see the catalogs subcommand)")
+	fdsnCmd.PersistentFlags().StringVar(&FDSNContributorFlag, "contributor", "", "limit to events contributed by a network (see the contributors subcommand)")
+	addFilterFlags(fdsnCmd.PersistentFlags(), &FDSNFilterFlags)
 }
 
 var fdsnCmd = &cobra.Command{
@@ -19,3 +31,28 @@ var fdsnCmd = &cobra.Command{
 	Long: `Retrieve historical earthquake records from the International
 	Federation of Digital Seismograph Networks (FDSN)`,
 }
+
+// fdsnFlags gathers the persistent fdsn flag values, the named places from
+// the configuration file and the parsed polygon for the logic package.
+func fdsnFlags() (logic.FDSNFlags, error) {
+	ff, err := withPlaces(FDSNFilterFlags)
+	if err != nil {
+		return logic.FDSNFlags{}, err
+	}
+	if err := ff.LoadPolygon(); err != nil {
+		return logic.FDSNFlags{}, err
+	}
+
+	return logic.FDSNFlags{
+		Mag:         FDSNMagFlag,
+		Format:      FDSNFormatFlag,
+		DateTime:    FDSNDateTimeFlag,
+		Updated:     FDSNUpdatedFlag,
+		OrderBy:     FDSNOrderFlag,
+		Limit:       FDSNLimitFlag,
+		Offset:      FDSNOffsetFlag,
+		Catalog:     FDSNCatalogFlag,
+		Contributor: FDSNContributorFlag,
+		FilterFlags: ff,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jbronder/geteq/logic"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	fdsnCmd.AddCommand(catalogsCmd)
+	fdsnCmd.AddCommand(contributorsCmd)
+}
+
+var catalogsCmd = &cobra.Command{
+	Use:   "catalogs",
+	Short: "list the catalogs available to --catalog",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listValues(logic.CATALOGSMETHOD)
+	},
+}
+
+var contributorsCmd = &cobra.Command{
+	Use:   "contributors",
+	Short: "list the contributors available to --contributor",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listValues(logic.CONTRIBUTORSMETHOD)
+	},
+}
+
+// listValues outputs the valid values the service lists for a parameter.
+func listValues(endCmd string) error {
+	if FDSNFormatFlag != "table" && FDSNFormatFlag != "json" {
+		return logic.ErrFlagFormatOption
+	}
+
+	endpoint, err := logic.ExtractMethodURL(endCmd)
+	if err != nil {
+		return err
+	}
+
+	content, err := logic.RequestContent(endpoint)
+	if err != nil {
+		return err
+	}
+
+	values, err := logic.ExtractValueList(content)
+	if err != nil {
+		return err
+	}
+	return logic.WriteValueList(os.Stdout, FDSNFormatFlag, values)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jbronder/geteq/logic"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	fdsnCmd.AddCommand(countCmd)
+}
+
+var countCmd = &cobra.Command{
+	Use:     "count",
+	Aliases: []string{"c"},
+	Short:   "count the records matching a query",
+	Long: `Count the records matching a query along with the most records the
+service returns in a single response. Filters applied locally, such as
+--polygon, are not part of the count.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags, err := fdsnFlags()
+		if err != nil {
+			return err
+		}
+
+		if FDSNFormatFlag != "table" && FDSNFormatFlag != "json" {
+			return logic.ErrFlagFormatOption
+		}
+
+		filters, err := logic.ExtractFDSNFilters(flags)
+		if err != nil {
+			return err
+		}
+
+		if len(filters) != 0 {
+			fmt.Fprintln(os.Stderr, "Note: filters applied locally are not part of the count")
+		}
+
+		count, err := logic.RequestCount(flags)
+		if err != nil {
+			return err
+		}
+
+		switch FDSNFormatFlag {
+		case "table":
+			logic.StdoutCount(count)
+		case "json":
+			return json.NewEncoder(os.Stdout).Encode(count)
+		}
+		return nil
+	},
+}
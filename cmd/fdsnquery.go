@@ -2,42 +2,82 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/jbronder/geteq/logic"
 	"github.com/spf13/cobra"
 )
 
+var FDSNOrderFlag string
+var FDSNLimitFlag int
+var FDSNOffsetFlag int
+
 func init() {
 	fdsnCmd.AddCommand(queryCmd)
+	queryCmd.Flags().StringVar(&FDSNOrderFlag, "order-by", "", "order of the records: {time, time-asc, magnitude, magnitude-asc}")
+	queryCmd.Flags().IntVar(&FDSNLimitFlag, "limit", 0, "maximum number of records to return (at most 20000)")
+	queryCmd.Flags().IntVar(&FDSNOffsetFlag, "offset", 0, "return records starting at this record count, starting at 1")
+	addOutputFlags(queryCmd.Flags(), &FDSNOutputFlags)
 }
 
 var queryCmd = &cobra.Command{
 	Use:     "query",
 	Aliases: []string{"q"},
 	Short:   "run a record query",
+	Long: `Run a record query. Table and JSON output without --limit first ask the
+FDSN count method and transparently page through results larger than the
+service allows in a single response. So do the formats the service writes itself, such as CSV,
+when local filters apply or the CSV columns or time format are customized.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		endpoint, err := logic.ExtractFDSNParams("query", FDSNMagFlag, FDSNFormatFlag, FDSNDateTimeFlag)
+		flags, err := fdsnFlags()
 		if err != nil {
 			return err
 		}
 
-		content, err := logic.RequestContent(endpoint)
+		filters, err := logic.ExtractFDSNFilters(flags)
 		if err != nil {
 			return err
 		}
 
-		switch FDSNFormatFlag {
-		case "table":
-			features, err := logic.ExtractFeatures(content)
+		endpoint, err := logic.ExtractFDSNParams("query", flags)
+		if err != nil {
+			return err
+		}
+
+		if err := FDSNOutputFlags.Check(FDSNFormatFlag); err != nil {
+			return err
+		}
+		rf, err := logic.ExtractRecordFormat(FDSNOutputFlags)
+		if err != nil {
+			return err
+		}
+
+		if localOutput(FDSNFormatFlag, len(filters) != 0, FDSNOutputFlags) {
+			res, err := logic.RequestAllFeatures(flags, os.Stderr)
 			if err != nil {
 				return err
 			}
-			logic.StdoutFeatures(features)
-		case "json":
-			fallthrough
+			res.Features = logic.FilterFeatures(res.Features, filters...)
+			return writeFeatures(FDSNFormatFlag, rf, res)
+		}
+
+		if len(filters) != 0 {
+			return logic.ErrFlagLocalFormat
+		}
+
+		content, err := logic.RequestContent(endpoint)
+		if err != nil {
+			return err
+		}
+
+		switch FDSNFormatFlag {
 		case "csv":
 			fallthrough
 		case "text":
+			fallthrough
+		case "quakeml":
+			fallthrough
+		case "xml":
 			fmt.Println(string(content))
 		}
 		return nil
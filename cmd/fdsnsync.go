@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jbronder/geteq/logic"
+	"github.com/spf13/cobra"
+)
+
+var SyncStateFlag string
+
+func init() {
+	fdsnCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringVar(&SyncStateFlag, "state", logic.DefaultSyncStatePath(), "file remembering the last update time retrieved for each query")
+	addOutputFlags(syncCmd.Flags(), &FDSNOutputFlags)
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "retrieve the records created or updated since the last sync",
+	Long: `Retrieve the records created or updated since the last successful run of
+the same query. The latest update time among the retrieved records is kept in
+a state file and passed as --updated-after on the next run, so records
+updated within the same second may be retrieved again. The first run of a
+query retrieves every matching record.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags, err := fdsnFlags()
+		if err != nil {
+			return err
+		}
+
+		// Every matching record is retrieved, as the high-water mark would
+		// otherwise pass over records left out of a limited page.
+		flags.Limit = 0
+		flags.Offset = 0
+
+		if !localFormats[FDSNFormatFlag] {
+			return logic.ErrFlagFormatOption
+		}
+
+		if err := FDSNOutputFlags.Check(FDSNFormatFlag); err != nil {
+			return err
+		}
+		rf, err := logic.ExtractRecordFormat(FDSNOutputFlags)
+		if err != nil {
+			return err
+		}
+
+		filters, err := logic.ExtractFDSNFilters(flags)
+		if err != nil {
+			return err
+		}
+
+		key, err := logic.SyncKey(flags)
+		if err != nil {
+			return err
+		}
+
+		state, err := logic.LoadSyncState(SyncStateFlag)
+		if err != nil {
+			return err
+		}
+
+		mark, hasMark := state.Marks[key]
+		if hasMark && len(flags.Updated) == 0 {
+			flags.Updated = logic.FormatMark(mark)
+		}
+
+		res, err := logic.RequestAllFeatures(flags, os.Stderr)
+		if err != nil {
+			return err
+		}
+
+		mark = logic.HighWaterMark(res.Features, mark)
+		res.Features = logic.FilterFeatures(res.Features, filters...)
+		if err := writeFeatures(FDSNFormatFlag, rf, res); err != nil {
+			return err
+		}
+
+		if mark == 0 {
+			return nil
+		}
+		state.Marks[key] = mark
+		return state.Save(SyncStateFlag)
+	},
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/jbronder/geteq/logic"
+	"github.com/spf13/pflag"
+)
+
+// addFilterFlags registers the flags shared by every command that narrows
+// down a set of events and binds their values to ff.
+func addFilterFlags(flags *pflag.FlagSet, ff *logic.FilterFlags) {
+	flags.StringVar(&ff.BBox, "bbox", "", `geographic rectangle in degrees (e.g. minlat,maxlat,minlon,maxlon "32,42,-125,-114")`)
+	flags.StringVar(&ff.Radius, "radius", "", `circle around a point or named place in km or deg (e.g. lat,lon,radius "37.8,-122.4,150km" or place,radius "plant,2deg")`)
+	flags.StringVar(&ff.Polygon, "polygon", "", "file holding a GeoJSON or WKT polygon to search within")
+	flags.StringVar(&ff.Depth, "depth", "", `depth or depth range in km (e.g. low,high "0,70", ">300" or "<10")`)
+	flags.StringVar(&ff.EventType, "event-type", "", `event types (e.g. "earthquake,quarry blast,explosion")`)
+	flags.StringVar(&ff.ReviewStatus, "review-status", "", "review statuses: {automatic, reviewed, deleted}")
+	flags.StringVar(&ff.AlertLevel, "alert-level", "", `PAGER alert levels: {green, yellow, orange, red} (e.g. "orange,red")`)
+	flags.StringVar(&ff.Felt, "felt", "", `number of DYFI felt reports (e.g. ">10" or low,high "10,100")`)
+	flags.StringVar(&ff.Cdi, "cdi", "", `DYFI maximum community determined intensity (e.g. ">4.5" or low,high "3,6")`)
+	flags.StringVar(&ff.Mmi, "mmi", "", `ShakeMap maximum modified Mercalli intensity (e.g. ">6" or low,high "4,7")`)
+	flags.StringVar(&ff.Sig, "sig", "", `event significance (e.g. ">600" or low,high "100,600")`)
+	flags.BoolVar(&ff.Tsunami, "tsunami", false, "only large events in oceanic regions flagged for tsunami information")
+}
+
+// withPlaces returns ff along with the named places from the configuration
+// file. The file is only read when the radius refers to a named place.
+func withPlaces(ff logic.FilterFlags) (logic.FilterFlags, error) {
+	if !logic.UsesPlace(ff.Radius) {
+		return ff, nil
+	}
+
+	conf, err := logic.LoadConfig(ConfigFlag)
+	if err != nil {
+		return ff, err
+	}
+	ff.Places = conf.Places
+	return ff, nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jbronder/geteq/logic"
+	"github.com/spf13/pflag"
+)
+
+// localFormats lists the output formats that can be written from decoded
+// Features rather than forwarded from the server as is.
+var localFormats = map[string]bool{
+	"table":   true,
+	"json":    true,
+	"ndjson":  true,
+	"csv":     true,
+	"quakeml": true,
+	"xml":     true,
+	"kml":     true,
+	"kmz":     true,
+}
+
+// serverFormats lists the output formats the FDSN event service writes itself,
+// which are forwarded as is unless the Features must be transformed locally.
+var serverFormats = map[string]bool{
+	"csv":     true,
+	"text":    true,
+	"quakeml": true,
+	"xml":     true,
+}
+
+// addOutputFlags registers the flags shaping the records of locally written
+// output and binds their values to of.
+func addOutputFlags(flags *pflag.FlagSet, of *logic.OutputFlags) {
+	flags.StringVar(&of.Columns, "columns", "", `csv columns or flattened ndjson properties from the event properties and geometry (e.g. "id,time,latitude,longitude,depth,mag,place,felt")`)
+	flags.StringVar(&of.TimeFormat, "time-format", "", `csv and flattened ndjson time format: {iso, rfc3339, unix, millis} or a Go layout (e.g. "2006-01-02 15:04:05") (default: iso)`)
+	flags.BoolVar(&of.Flatten, "flatten", false, "write ndjson records of id, time, latitude, longitude, depth and the --columns properties (default: mag, place)")
+}
+
+// localOutput reports whether output in format must be written from decoded
+// Features: the server does not write the format, local filters apply to
+// the Features, or the records were customized.
+func localOutput(format string, filtered bool, of logic.OutputFlags) bool {
+	if !localFormats[format] {
+		return false
+	}
+	return !serverFormats[format] || filtered || (format == "csv" && of.Custom())
+}
+
+// writeFeatures outputs a response whose Features were transformed locally in
+// the requested format.
+func writeFeatures(format string, rf logic.RecordFormat, res *logic.USGSResponse) error {
+	switch format {
+	case "table":
+		logic.StdoutFeatures(res.Features)
+	case "json":
+		return logic.WriteJSON(os.Stdout, res)
+	case "ndjson":
+		return writeNDJSON(rf, res.Features)
+	case "csv":
+		return logic.WriteCSV(os.Stdout, res.Features, rf, true)
+	case "quakeml", "xml":
+		return logic.WriteQuakeML(os.Stdout, res.Features)
+	case "kml":
+		return logic.WriteKML(os.Stdout, res.Features)
+	case "kmz":
+		return logic.WriteKMZ(os.Stdout, res.Features)
+	default:
+		return logic.ErrFlagLocalFormat
+	}
+	return nil
+}
+
+// writeNDJSON outputs each Feature on a line of its own, flattened when the
+// record format asks for it.
+func writeNDJSON(rf logic.RecordFormat, features logic.Features) error {
+	if rf.Flatten {
+		return logic.WriteFlatNDJSON(os.Stdout, features, rf)
+	}
+	return logic.WriteNDJSON(os.Stdout, features)
+}
@@ -10,12 +10,26 @@ import (
 var RtFormatFlag string
 var RtMagFlag string
 var RtTimeFlag string
+var RtOrderFlag string
+var RtFilterFlags logic.FilterFlags
+var RtOutputFlags logic.OutputFlags
+
+// rtFeedFormats lists the output formats the realtime feeds serve directly;
+// any other format is written from the decoded Features.
+var rtFeedFormats = map[string]bool{
+	"table": true,
+	"csv":   true,
+	"json":  true,
+}
 
 func init() {
 	rootCmd.AddCommand(realtimeCmd)
-	realtimeCmd.Flags().StringVarP(&RtFormatFlag, "output", "o", "table", "output format options: {csv, json, table}")
-	realtimeCmd.Flags().StringVarP(&RtMagFlag, "mag", "m", "major", "magnitude options: {all, 1.0, 2.5, 4.5, major}")
-	realtimeCmd.Flags().StringVarP(&RtTimeFlag, "time", "t", "month", "time range options: {hour, day, week, month}")
+	realtimeCmd.Flags().StringVarP(&RtFormatFlag, "output", "o", "table", "output format options: {csv, json, kml, kmz, ndjson, quakeml, table, xml} (quakeml and xml write one origin and magnitude per event)")
+	realtimeCmd.Flags().StringVarP(&RtMagFlag, "mag", "m", "major", "magnitude options: {all, 1.0, 2.5, 4.5, major} or any minimum magnitude (e.g. 3.0)")
+	realtimeCmd.Flags().StringVarP(&RtTimeFlag, "time", "t", "month", "time range options: {hour, day, week, month} or any duration up to 30 days (e.g. 90m, 36h, 3d, 2w)")
+	realtimeCmd.Flags().StringVar(&RtOrderFlag, "order-by", "", "order of the records: {time, time-asc, magnitude, magnitude-asc}")
+	addFilterFlags(realtimeCmd.Flags(), &RtFilterFlags)
+	addOutputFlags(realtimeCmd.Flags(), &RtOutputFlags)
 }
 
 var realtimeCmd = &cobra.Command{
@@ -23,6 +37,31 @@ var realtimeCmd = &cobra.Command{
 	Aliases: []string{"real", "rt"},
 	Short:   "query real-time earthquake data",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := RtOutputFlags.Check(RtFormatFlag); err != nil {
+			return err
+		}
+
+		ff, err := withPlaces(RtFilterFlags)
+		if err != nil {
+			return err
+		}
+
+		filters, err := logic.ExtractFilters(ff)
+		if err != nil {
+			return err
+		}
+
+		rtFilters, err := logic.ExtractRTFilters(RtMagFlag, RtTimeFlag)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, rtFilters...)
+
+		if len(filters) != 0 || len(RtOrderFlag) != 0 || !rtFeedFormats[RtFormatFlag] ||
+			(RtFormatFlag == "csv" && RtOutputFlags.Custom()) {
+			return runLocalRealtime(filters)
+		}
+
 		fileEndpoint, err := logic.ExtractRTParams(RtFormatFlag, RtMagFlag, RtTimeFlag)
 		if err != nil {
 			return err
@@ -48,3 +87,39 @@ var realtimeCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// runLocalRealtime requests the GeoJSON feed regardless of the output format
+// so that the filters and ordering can run over the decoded Features.
+func runLocalRealtime(filters []logic.Filter) error {
+	if !localFormats[RtFormatFlag] {
+		return logic.ErrFlagFormatOption
+	}
+
+	if err := logic.ValidateOrder(RtOrderFlag); err != nil {
+		return err
+	}
+
+	rf, err := logic.ExtractRecordFormat(RtOutputFlags)
+	if err != nil {
+		return err
+	}
+
+	fileEndpoint, err := logic.ExtractRTParams("json", RtMagFlag, RtTimeFlag)
+	if err != nil {
+		return err
+	}
+	content, err := logic.RequestContent(fileEndpoint)
+	if err != nil {
+		return err
+	}
+
+	res, err := logic.ExtractResponse(content)
+	if err != nil {
+		return err
+	}
+	res.Features = logic.FilterFeatures(res.Features, filters...)
+	if err := logic.SortFeatures(res.Features, RtOrderFlag); err != nil {
+		return err
+	}
+	return writeFeatures(RtFormatFlag, rf, res)
+}
@@ -3,9 +3,16 @@ package cmd
 import (
 	"os"
 
+	"github.com/jbronder/geteq/logic"
 	"github.com/spf13/cobra"
 )
 
+var ConfigFlag string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&ConfigFlag, "config", logic.DefaultConfigPath(), "configuration file holding named places and notification rules")
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "geteq",
 	Short: "geteq returns real-time and historical earthquake records from USGS",
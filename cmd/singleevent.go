@@ -1,22 +1,69 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/jbronder/geteq/logic"
 	"github.com/spf13/cobra"
 )
 
+var (
+	EventProductsFlag bool
+	EventPagerFlag    bool
+)
+
 func init() {
 	queryCmd.AddCommand(singleEventCmd)
+	singleEventCmd.Flags().BoolVar(&EventProductsFlag, "products", false, "list every product of the event with its source, update time and content files")
+	singleEventCmd.Flags().BoolVar(&EventPagerFlag, "pager", false, "add the PAGER loss estimate: fatality and economic alert levels, population exposure and most exposed cities")
 }
 
 var singleEventCmd = &cobra.Command{
-	Use:     "event",
+	Use:     "event <eventid> [download|mechanism|dyfi|phases]",
 	Aliases: []string{"se", "e", "s"},
 	Short:   "Detailed information about a single event given an eventid",
-	Args:    cobra.ExactArgs(1),
+	Long: `Detailed information about a single event given an eventid.
+
+An optional view name after the eventid selects another view of the event:
+  download   save the content files of the event's products into a directory
+  mechanism  moment tensor or focal mechanism with a beachball diagram
+  dyfi       DYFI intensity against distance (-o csv lists every cell)
+  phases     phase picks and arrivals of the preferred origin`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		view := ""
+		if len(args) == 2 {
+			view = args[1]
+		}
+		if err := checkViewFlags(cmd, view); err != nil {
+			return err
+		}
+
+		if len(args) == 2 {
+			switch args[1] {
+			case "download":
+				return runDownload(args[0])
+			case "mechanism":
+				return runMechanism(args[0])
+			case "dyfi":
+				return runDYFI(args[0])
+			case "phases":
+				return runPhases(args[0])
+			default:
+				return fmt.Errorf("unknown event view %q", args[1])
+			}
+		}
+
+		if EventProductsFlag {
+			return runProducts(args[0])
+		}
+		if EventPagerFlag {
+			return runPager(args[0])
+		}
+
 		endpoint, err := logic.ExtractId("query", FDSNFormatFlag, args[0])
 		if err != nil {
 			return err
@@ -39,9 +86,108 @@ var singleEventCmd = &cobra.Command{
 		case "csv":
 			fallthrough
 		case "text":
+			fallthrough
+		case "quakeml":
+			fallthrough
+		case "xml":
 			fmt.Println(string(content))
 		}
 
 		return nil
 	},
 }
+
+// eventViewFlags lists the flags that only apply to one view of the event
+// command.
+var eventViewFlags = map[string][]string{
+	"download": {"dir", "product", "glob"},
+	"dyfi":     {"dyfi-source", "bin-width"},
+}
+
+// checkViewFlags rejects flags set on the command line that belong to a view
+// other than the selected one.
+func checkViewFlags(cmd *cobra.Command, view string) error {
+	for flagView, names := range eventViewFlags {
+		if flagView == view {
+			continue
+		}
+		for _, name := range names {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s option only applies to the %s view", name, flagView)
+			}
+		}
+	}
+	return nil
+}
+
+// runProducts outputs the products listed in the detail document of an event.
+func runProducts(id string) error {
+	if FDSNFormatFlag != "table" && FDSNFormatFlag != "json" {
+		return logic.ErrFlagFormatOption
+	}
+
+	detail, err := logic.RequestDetail(id)
+	if err != nil {
+		return err
+	}
+
+	if FDSNFormatFlag == "json" {
+		return json.NewEncoder(os.Stdout).Encode(detail.Props.Products)
+	}
+	logic.StdoutProducts(detail)
+	return nil
+}
+
+// runPager outputs the single event view followed by the event's PAGER loss
+// estimate. JSON output adds the estimate to the event as a "pager" member,
+// which is null when the event has no losspager product.
+func runPager(id string) error {
+	if FDSNFormatFlag != "table" && FDSNFormatFlag != "json" {
+		return logic.ErrFlagFormatOption
+	}
+
+	endpoint, err := logic.ExtractId("query", FDSNFormatFlag, id)
+	if err != nil {
+		return err
+	}
+
+	content, err := logic.RequestContent(endpoint)
+	if err != nil {
+		return err
+	}
+
+	detail, err := logic.ExtractDetail(content)
+	if err != nil {
+		return err
+	}
+
+	// Only an event without a losspager product has no estimate; a product
+	// missing one of its files is an error.
+	var pager *logic.Pager
+	if _, err := detail.Props.Products.Preferred(logic.PRODUCTLOSSPAGER); err == nil {
+		if pager, err = logic.RequestPager(detail); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, logic.ErrProductMissing) {
+		return err
+	}
+
+	if FDSNFormatFlag == "json" {
+		var event map[string]json.RawMessage
+		if err := json.Unmarshal(content, &event); err != nil {
+			return err
+		}
+		if event["pager"], err = json.Marshal(pager); err != nil {
+			return err
+		}
+		return json.NewEncoder(os.Stdout).Encode(event)
+	}
+
+	feature, err := logic.ExtractSingleFeature(content)
+	if err != nil {
+		return err
+	}
+	logic.StdoutSingleEvent(feature)
+	logic.StdoutPager(pager)
+	return nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jbronder/geteq/logic"
+	"github.com/spf13/cobra"
+)
+
+var WatchFormatFlag string
+var WatchMagFlag string
+var WatchIntervalFlag time.Duration
+var WatchNoNotifyFlag bool
+var WatchFilterFlags logic.FilterFlags
+var WatchOutputFlags logic.OutputFlags
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringVarP(&WatchFormatFlag, "output", "o", "table", "output format options: {csv, ndjson, table}")
+	watchCmd.Flags().StringVarP(&WatchMagFlag, "mag", "m", "2.5", "magnitude options: {all, 1.0, 2.5, 4.5, major} or any minimum magnitude (e.g. 3.0)")
+	watchCmd.Flags().DurationVarP(&WatchIntervalFlag, "interval", "i", time.Minute, "time between polls of the feed (e.g. 30s, 5m)")
+	watchCmd.Flags().BoolVar(&WatchNoNotifyFlag, "no-notify", false, "skip the notification rules of the configuration file")
+	addFilterFlags(watchCmd.Flags(), &WatchFilterFlags)
+	addOutputFlags(watchCmd.Flags(), &WatchOutputFlags)
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "stream new and updated earthquake events as they appear",
+	Long: `Poll a real-time feed on an interval and output only the events that are
+new or were updated since the previous poll. The first poll outputs every
+event in the feed. Failed requests are retried with an increasing back off.
+Stop watching with Ctrl+C.
+
+Output events are also delivered to the sinks of every notification rule they
+match in the configuration file: a command receiving the event as JSON on
+stdin, a webhook receiving it as a JSON POST, or a file it is appended to.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// The configuration file is only needed for its notification rules and
+		// for a radius around a named place.
+		conf := new(logic.Config)
+		var err error
+		if !WatchNoNotifyFlag || logic.UsesPlace(WatchFilterFlags.Radius) {
+			if conf, err = logic.LoadConfig(ConfigFlag); err != nil {
+				return err
+			}
+		}
+
+		ff := WatchFilterFlags
+		ff.Places = conf.Places
+
+		filters, err := logic.ExtractFilters(ff)
+		if err != nil {
+			return err
+		}
+
+		feed, err := logic.WatchFeed(WatchIntervalFlag)
+		if err != nil {
+			return err
+		}
+
+		endpoint, err := logic.ExtractRTParams("json", WatchMagFlag, feed)
+		if err != nil {
+			return err
+		}
+
+		rtFilters, err := logic.ExtractRTFilters(WatchMagFlag, feed)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, rtFilters...)
+
+		alerter := new(logic.Alerter)
+		if !WatchNoNotifyFlag {
+			if alerter, err = logic.NewAlerter(conf.Rules, conf.Places); err != nil {
+				return err
+			}
+		}
+
+		if err := WatchOutputFlags.Check(WatchFormatFlag); err != nil {
+			return err
+		}
+		rf, err := logic.ExtractRecordFormat(WatchOutputFlags)
+		if err != nil {
+			return err
+		}
+
+		var output func(logic.Features) error
+		switch WatchFormatFlag {
+		case "table":
+			logic.StdoutFeaturesHeader()
+			output = func(features logic.Features) error {
+				logic.StdoutFeatureRows(features)
+				return nil
+			}
+		case "ndjson":
+			output = func(features logic.Features) error {
+				return writeNDJSON(rf, features)
+			}
+		case "csv":
+			if err := logic.WriteCSV(os.Stdout, nil, rf, true); err != nil {
+				return err
+			}
+			output = func(features logic.Features) error {
+				return logic.WriteCSV(os.Stdout, features, rf, false)
+			}
+		default:
+			return logic.ErrFlagFormatOption
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// The events already in the feed when watching starts are output but
+		// not notified, as they are not new.
+		handle := func(features logic.Features, initial bool) error {
+			if err := output(features); err != nil {
+				return err
+			}
+			if !initial {
+				alerter.Alert(ctx, features, os.Stderr)
+			}
+			return nil
+		}
+
+		defer alerter.Close()
+		return logic.Watch(ctx, endpoint, WatchIntervalFlag, filters, handle, os.Stderr)
+	},
+}
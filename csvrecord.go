@@ -89,7 +89,7 @@ func processFloat(rec string) float64 {
 		return 0
 	}
 	if floatField, err := strconv.ParseFloat(rec, 64); err != nil {
-		fmt.Fprint(os.Stderr, "%v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		return -1
 	} else {
 		return floatField
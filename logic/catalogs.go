@@ -0,0 +1,71 @@
+package logic
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+const (
+	CATALOGSMETHOD     = "catalogs"
+	CONTRIBUTORSMETHOD = "contributors"
+)
+
+// valueList is an XML document listing the values of one FDSN parameter,
+// e.g. <Catalogs><Catalog>us</Catalog>...</Catalogs>.
+type valueList struct {
+	Values []string `xml:",any"`
+}
+
+// ExtractMethodURL returns the URL of an FDSN method that takes no
+// parameters, such as the catalogs and contributors methods.
+func ExtractMethodURL(endCmd string) (string, error) {
+	return url.JoinPath(FDSNENDPOINT, endCmd)
+}
+
+// ExtractValueList unmarshals the valid values of an FDSN parameter from the
+// response of the catalogs or contributors method.
+func ExtractValueList(res []byte) ([]string, error) {
+	var list valueList
+	err := xml.Unmarshal(res, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(list.Values))
+	for _, val := range list.Values {
+		if val = strings.TrimSpace(val); len(val) != 0 {
+			values = append(values, val)
+		}
+	}
+	return values, nil
+}
+
+// WriteValueList outputs the valid values of an FDSN parameter one per line,
+// or as a JSON array when format is json.
+func WriteValueList(w io.Writer, format string, values []string) error {
+	switch format {
+	case "table":
+		for _, val := range values {
+			fmt.Fprintln(w, val)
+		}
+	case "json":
+		return json.NewEncoder(w).Encode(values)
+	default:
+		return ErrFlagFormatOption
+	}
+	return nil
+}
+
+// validateSource checks a catalog or contributor flag value, which names a
+// single source.
+func validateSource(sFlag string, errOpt error) (string, error) {
+	source := strings.TrimSpace(sFlag)
+	if strings.ContainsAny(source, " ,&?=/") {
+		return "", errOpt
+	}
+	return source, nil
+}
@@ -0,0 +1,26 @@
+package logic
+
+import (
+	"slices"
+	"testing"
+)
+
+type ValueListTest struct {
+	in  string
+	out []string
+}
+
+func TestExtractValueList(t *testing.T) {
+	vTests := []ValueListTest{
+		{`<?xml version="1.0"?><Catalogs><Catalog>ak</Catalog><Catalog>us</Catalog></Catalogs>`, []string{"ak", "us"}},
+		{"<Contributors>\n  <Contributor>ci</Contributor>\n  <Contributor> nc </Contributor>\n</Contributors>", []string{"ci", "nc"}},
+		{`<Catalogs></Catalogs>`, []string{}},
+	}
+
+	for _, test := range vTests {
+		values, err := ExtractValueList([]byte(test.in))
+		if !slices.Equal(values, test.out) || err != nil {
+			t.Errorf("ExtractValueList(%q) = %v %v; want %v", test.in, values, err, test.out)
+		}
+	}
+}
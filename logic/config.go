@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Place is a named location that may stand in for raw coordinates in flag
+// values, such as a monitored facility.
+type Place struct {
+	Lat float64 `json:"latitude"`
+	Lon float64 `json:"longitude"`
+}
+
+// Config is the local geteq configuration file.
+type Config struct {
+	Places map[string]Place `json:"places"`
+	Rules  []Rule           `json:"rules"`
+}
+
+// DefaultConfigPath returns the location of the configuration file within the
+// user's configuration directory, e.g. ~/.config/geteq/config.json on Linux.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "geteq", "config.json")
+}
+
+// LoadConfig reads the configuration file at path. A missing file is not an
+// error and returns an empty Config.
+func LoadConfig(path string) (*Config, error) {
+	conf := new(Config)
+	if len(path) == 0 {
+		return conf, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return conf, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, conf); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// CountResponse is the GeoJSON response of the FDSN count method.
+type CountResponse struct {
+	Count      int `json:"count"`
+	MaxAllowed int `json:"maxAllowed"`
+}
+
+// ExtractCount unmarshals the response of the FDSN count method.
+func ExtractCount(res []byte) (*CountResponse, error) {
+	c := new(CountResponse)
+	err := json.Unmarshal(res, c)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// RequestCount asks the FDSN count method how many records match the flags.
+func RequestCount(flags FDSNFlags) (*CountResponse, error) {
+	flags.Format = "json"
+	flags.OrderBy = ""
+	flags.Limit = 0
+	flags.Offset = 0
+
+	endpoint, err := ExtractFDSNParams("count", flags)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := requestContent(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractCount(content)
+}
+
+// StdoutCount outputs the number of matching records along with the most
+// records the service returns in a single response.
+func StdoutCount(c *CountResponse) {
+	fmt.Fprintf(os.Stdout, "Count: %d\n", c.Count)
+	fmt.Fprintf(os.Stdout, "Max Allowed: %d\n", c.MaxAllowed)
+}
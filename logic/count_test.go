@@ -0,0 +1,32 @@
+package logic
+
+import "testing"
+
+type CountTest struct {
+	in  string
+	out *CountResponse
+	err bool
+}
+
+func TestExtractCount(t *testing.T) {
+	cTests := []CountTest{
+		{`{"count":1530,"maxAllowed":20000}`, &CountResponse{1530, 20000}, false},
+		{`{"count":0,"maxAllowed":20000}`, &CountResponse{0, 20000}, false},
+		{`{"count":42}`, &CountResponse{42, 0}, false},
+		{`{"count":"many"}`, nil, true},
+		{`1530`, nil, true},
+		{`{"count":`, nil, true},
+		{``, nil, true},
+	}
+
+	for _, test := range cTests {
+		c, err := ExtractCount([]byte(test.in))
+		if (err != nil) != test.err {
+			t.Errorf("ExtractCount(%q) error = %v; want error %v", test.in, err, test.err)
+			continue
+		}
+		if test.out != nil && *c != *test.out {
+			t.Errorf("ExtractCount(%q) = %v; want %v", test.in, *c, *test.out)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package logic
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrFlagColumnsOption = errors.New("--columns option invalid")
+var ErrFlagTimeFormatOption = errors.New("--time-format option invalid")
+var ErrFlagFlattenOption = errors.New("--flatten option invalid")
+
+// CSVTIMEFORMAT is the time format of the USGS CSV feeds.
+const CSVTIMEFORMAT = "2006-01-02T15:04:05.000Z"
+
+// CSVColumns lists the columns written by default: the columns of the USGS
+// CSV feeds and of the FDSN csv format, in the same order. The GeoJSON the
+// records are written from has no horizontalError, depthError, magError,
+// magNst, locationSource or magSource, so those columns are left empty.
+var CSVColumns = []string{
+	"time", "latitude", "longitude", "depth", "mag", "magType", "nst", "gap",
+	"dmin", "rms", "net", "id", "updated", "place", "type", "horizontalError",
+	"depthError", "magError", "magNst", "status", "locationSource", "magSource",
+}
+
+// csvFields maps a column name onto the value of a Feature in that column.
+var csvFields = map[string]func(f Feature, c RecordFormat) string{
+	"time":      func(f Feature, c RecordFormat) string { return c.formatTime(f.Props.Time) },
+	"latitude":  func(f Feature, c RecordFormat) string { return coordinate(f, 1) },
+	"longitude": func(f Feature, c RecordFormat) string { return coordinate(f, 0) },
+	"depth":     func(f Feature, c RecordFormat) string { return coordinate(f, 2) },
+	"mag":       func(f Feature, c RecordFormat) string { return optional(f.Props.Mag, formatFloat) },
+	"magType":   func(f Feature, c RecordFormat) string { return f.Props.MagType },
+	"nst":       func(f Feature, c RecordFormat) string { return optional(f.Props.Nst, strconv.Itoa) },
+	"gap":       func(f Feature, c RecordFormat) string { return optional(f.Props.Gap, formatFloat) },
+	"dmin":      func(f Feature, c RecordFormat) string { return optional(f.Props.Dmin, formatFloat) },
+	"rms":       func(f Feature, c RecordFormat) string { return optional(f.Props.Rms, formatFloat) },
+	"net":       func(f Feature, c RecordFormat) string { return f.Props.Net },
+	"id":        func(f Feature, c RecordFormat) string { return f.Id },
+	"updated":   func(f Feature, c RecordFormat) string { return c.formatTime(f.Props.Updated) },
+	"place":     func(f Feature, c RecordFormat) string { return f.Props.Place },
+	"type":      func(f Feature, c RecordFormat) string { return f.Props.Type },
+	"status":    func(f Feature, c RecordFormat) string { return f.Props.Status },
+	"tz":        func(f Feature, c RecordFormat) string { return strconv.Itoa(f.Props.Tz) },
+	"url":       func(f Feature, c RecordFormat) string { return f.Props.Url },
+	"detail":    func(f Feature, c RecordFormat) string { return f.Props.Detail },
+	"felt":      func(f Feature, c RecordFormat) string { return optional(f.Props.Felt, strconv.Itoa) },
+	"cdi":       func(f Feature, c RecordFormat) string { return optional(f.Props.Cdi, formatFloat) },
+	"mmi":       func(f Feature, c RecordFormat) string { return optional(f.Props.Mmi, formatFloat) },
+	"alert":     func(f Feature, c RecordFormat) string { return optionalf("%s", f.Props.Alert) },
+	"tsunami":   func(f Feature, c RecordFormat) string { return strconv.Itoa(f.Props.Tsunami) },
+	"sig":       func(f Feature, c RecordFormat) string { return optional(f.Props.Sig, strconv.Itoa) },
+	"code":      func(f Feature, c RecordFormat) string { return f.Props.Code },
+	"ids":       func(f Feature, c RecordFormat) string { return f.Props.Ids },
+	"sources":   func(f Feature, c RecordFormat) string { return f.Props.Sources },
+	"types":     func(f Feature, c RecordFormat) string { return f.Props.Types },
+
+	"horizontalError": unavailable,
+	"depthError":      unavailable,
+	"magError":        unavailable,
+	"magNst":          unavailable,
+	"locationSource":  unavailable,
+	"magSource":       unavailable,
+}
+
+// unavailable is the value of the CSV columns missing from GeoJSON.
+func unavailable(f Feature, c RecordFormat) string {
+	return ""
+}
+
+// namedTimeFormats maps the names accepted by --time-format onto a function
+// formatting epoch milliseconds.
+var namedTimeFormats = map[string]func(ms int64) string{
+	"iso":     func(ms int64) string { return time.UnixMilli(ms).UTC().Format(CSVTIMEFORMAT) },
+	"rfc3339": func(ms int64) string { return time.UnixMilli(ms).UTC().Format(time.RFC3339) },
+	"unix":    func(ms int64) string { return formatFloat(float64(ms) / 1000) },
+	"millis":  func(ms int64) string { return strconv.FormatInt(ms, 10) },
+}
+
+// layoutProbe differs from the reference time of Go layouts in every element,
+// so formatting it changes any layout holding at least one element.
+var layoutProbe = time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+
+// OutputFlags holds the user input flag values that shape the records of
+// output written from decoded Features.
+type OutputFlags struct {
+	Columns    string
+	TimeFormat string
+	Flatten    bool
+}
+
+// Custom reports whether the flags ask for records that differ from the ones
+// the server writes itself.
+func (of OutputFlags) Custom() bool {
+	return len(strings.TrimSpace(of.Columns)) != 0 || len(strings.TrimSpace(of.TimeFormat)) != 0
+}
+
+// Check reports the flags that have no effect on output in format: columns
+// and time formats shape csv and flattened ndjson records only, and only
+// ndjson records are flattened.
+func (of OutputFlags) Check(format string) error {
+	flat := format == "csv" || (format == "ndjson" && of.Flatten)
+	if len(strings.TrimSpace(of.Columns)) != 0 && !flat {
+		return ErrFlagColumnsOption
+	}
+	if len(strings.TrimSpace(of.TimeFormat)) != 0 && !flat {
+		return ErrFlagTimeFormatOption
+	}
+	if of.Flatten && format != "ndjson" {
+		return ErrFlagFlattenOption
+	}
+	return nil
+}
+
+// RecordFormat selects the columns and time format of flat records, and
+// whether NDJSON records are flattened. The zero value writes CSVColumns with
+// times formatted as CSVTIMEFORMAT.
+type RecordFormat struct {
+	Columns    []string
+	TimeFormat string
+	Flatten    bool
+}
+
+// ExtractRecordFormat resolves the --columns and --time-format flag values. The
+// columns are a comma separated list of the names of CSVColumns and of the
+// other properties of a Feature (tz, url, detail, felt, cdi, mmi, alert,
+// tsunami, sig, code, ids, sources, types). The time format is one of iso,
+// rfc3339, unix (seconds), millis, or a Go time layout such as
+// "2006-01-02 15:04:05".
+func ExtractRecordFormat(of OutputFlags) (RecordFormat, error) {
+	c := RecordFormat{Flatten: of.Flatten}
+
+	if columns := strings.TrimSpace(of.Columns); len(columns) != 0 {
+		for _, column := range strings.Split(columns, ",") {
+			column = strings.TrimSpace(column)
+			if _, ok := csvFields[column]; !ok {
+				return RecordFormat{}, ErrFlagColumnsOption
+			}
+			c.Columns = append(c.Columns, column)
+		}
+	}
+
+	c.TimeFormat = strings.TrimSpace(of.TimeFormat)
+	if _, named := namedTimeFormats[strings.ToLower(c.TimeFormat)]; named {
+		c.TimeFormat = strings.ToLower(c.TimeFormat)
+	} else if len(c.TimeFormat) != 0 && layoutProbe.Format(c.TimeFormat) == c.TimeFormat {
+		// A layout without any time element formats every time the same.
+		return RecordFormat{}, ErrFlagTimeFormatOption
+	}
+
+	return c, nil
+}
+
+func (c RecordFormat) columns() []string {
+	if len(c.Columns) == 0 {
+		return CSVColumns
+	}
+	return c.Columns
+}
+
+func (c RecordFormat) formatTime(ms int64) string {
+	if len(c.TimeFormat) == 0 {
+		return formatMillis(ms)
+	}
+	if format, ok := namedTimeFormats[c.TimeFormat]; ok {
+		return format(ms)
+	}
+	return time.UnixMilli(ms).UTC().Format(c.TimeFormat)
+}
+
+// WriteCSV serializes Features as CSV records in the given format, preceded
+// by a header record when header is set.
+func WriteCSV(w io.Writer, features Features, format RecordFormat, header bool) error {
+	columns := format.columns()
+
+	csvWriter := csv.NewWriter(w)
+	if header {
+		if err := csvWriter.Write(columns); err != nil {
+			return err
+		}
+	}
+
+	record := make([]string, len(columns))
+	for _, f := range features {
+		for i, column := range columns {
+			record[i] = csvFields[column](f, format)
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func formatMillis(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(CSVTIMEFORMAT)
+}
+
+func coordinate(f Feature, i int) string {
+	if len(f.Geo.Coordinates) <= i {
+		return ""
+	}
+	return formatFloat(f.Geo.Coordinates[i])
+}
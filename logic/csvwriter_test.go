@@ -0,0 +1,114 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	features := Features{
+		{
+			Id:    "ci40012345",
+			Props: Properties{Mag: ptr(3.2), Place: `5 km NNE of "Ridgecrest", CA`, Time: 1718452800123, Updated: 1718452900000, MagType: "ml", Net: "ci", Type: "earthquake", Status: "reviewed", Gap: ptr(0.0)},
+			Geo:   Geometry{Coordinates: []float64{-117.6, 35.7, 8.2}},
+		},
+	}
+
+	var b strings.Builder
+	if err := WriteCSV(&b, features, RecordFormat{}, true); err != nil {
+		t.Fatalf("WriteCSV() = %v", err)
+	}
+
+	want := "time,latitude,longitude,depth,mag,magType,nst,gap,dmin,rms,net,id,updated,place,type,horizontalError,depthError,magError,magNst,status,locationSource,magSource\n" +
+		`2024-06-15T12:00:00.123Z,35.7,-117.6,8.2,3.2,ml,,0,,,ci,ci40012345,2024-06-15T12:01:40.000Z,"5 km NNE of ""Ridgecrest"", CA",earthquake,,,,,reviewed,,` + "\n"
+	if b.String() != want {
+		t.Errorf("WriteCSV() = %q; want %q", b.String(), want)
+	}
+}
+
+type RecordFormatTest struct {
+	in  OutputFlags
+	out RecordFormat
+	err error
+}
+
+func TestExtractRecordFormat(t *testing.T) {
+	cTests := []RecordFormatTest{
+		{OutputFlags{}, RecordFormat{}, nil},
+		{OutputFlags{Columns: "id, time,mag,place,felt", TimeFormat: "Unix"}, RecordFormat{Columns: []string{"id", "time", "mag", "place", "felt"}, TimeFormat: "unix"}, nil},
+		{OutputFlags{TimeFormat: "2006-01-02 15:04"}, RecordFormat{TimeFormat: "2006-01-02 15:04"}, nil},
+		{OutputFlags{TimeFormat: "Monday"}, RecordFormat{TimeFormat: "Monday"}, nil},
+		{OutputFlags{Columns: "id,magnitude"}, RecordFormat{}, ErrFlagColumnsOption},
+		{OutputFlags{Columns: "id,"}, RecordFormat{}, ErrFlagColumnsOption},
+		{OutputFlags{TimeFormat: "epoch"}, RecordFormat{}, ErrFlagTimeFormatOption},
+	}
+
+	for _, test := range cTests {
+		c, err := ExtractRecordFormat(test.in)
+		if err != test.err || strings.Join(c.Columns, ",") != strings.Join(test.out.Columns, ",") || c.TimeFormat != test.out.TimeFormat {
+			t.Errorf("ExtractRecordFormat(%+v) = %+v %v; want %+v %v", test.in, c, err, test.out, test.err)
+		}
+	}
+}
+
+func TestWriteRecordFormat(t *testing.T) {
+	features := Features{
+		{
+			Id:    "ci40012345",
+			Props: Properties{Mag: ptr(3.2), Place: "Ridgecrest, CA", Time: 1718452800123, Updated: 1718452900000, Felt: ptr(12)},
+			Geo:   Geometry{Coordinates: []float64{-117.6, 35.7}},
+		},
+	}
+
+	fTests := []struct {
+		in  OutputFlags
+		out string
+	}{
+		{OutputFlags{Columns: "id,time,depth,place,felt"}, "ci40012345,2024-06-15T12:00:00.123Z,,\"Ridgecrest, CA\",12\n"},
+		{OutputFlags{Columns: "time,updated", TimeFormat: "unix"}, "1718452800.123,1718452900\n"},
+		{OutputFlags{Columns: "time", TimeFormat: "millis"}, "1718452800123\n"},
+		{OutputFlags{Columns: "time", TimeFormat: "rfc3339"}, "2024-06-15T12:00:00Z\n"},
+		{OutputFlags{Columns: "time", TimeFormat: "02/01/2006 15:04"}, "15/06/2024 12:00\n"},
+	}
+
+	for _, test := range fTests {
+		format, err := ExtractRecordFormat(test.in)
+		if err != nil {
+			t.Fatalf("ExtractRecordFormat(%+v) = %v", test.in, err)
+		}
+
+		var b strings.Builder
+		if err := WriteCSV(&b, features, format, true); err != nil {
+			t.Fatalf("WriteCSV() = %v", err)
+		}
+		if want := test.in.Columns + "\n" + test.out; b.String() != want {
+			t.Errorf("WriteCSV(%+v) = %q; want %q", test.in, b.String(), want)
+		}
+	}
+}
+
+type OutputCheckTest struct {
+	format string
+	in     OutputFlags
+	err    error
+}
+
+func TestOutputFlagsCheck(t *testing.T) {
+	oTests := []OutputCheckTest{
+		{"table", OutputFlags{}, nil},
+		{"csv", OutputFlags{Columns: "id,mag", TimeFormat: "unix"}, nil},
+		{"ndjson", OutputFlags{Columns: "felt", TimeFormat: "millis", Flatten: true}, nil},
+		{"ndjson", OutputFlags{Flatten: true}, nil},
+		{"ndjson", OutputFlags{Columns: "felt"}, ErrFlagColumnsOption},
+		{"json", OutputFlags{Columns: "id,mag"}, ErrFlagColumnsOption},
+		{"table", OutputFlags{TimeFormat: "unix"}, ErrFlagTimeFormatOption},
+		{"kml", OutputFlags{TimeFormat: "rfc3339"}, ErrFlagTimeFormatOption},
+		{"csv", OutputFlags{Flatten: true}, ErrFlagFlattenOption},
+	}
+
+	for _, test := range oTests {
+		if err := test.in.Check(test.format); err != test.err {
+			t.Errorf("Check(%q) of %+v = %v; want %v", test.format, test.in, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package logic
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+var ErrProductMissing = errors.New("product unavailable for this event")
+var ErrContentMissing = errors.New("content file unavailable for this product")
+
+// Product types of the event detail document.
+const (
+	PRODUCTORIGIN         = "origin"
+	PRODUCTPHASEDATA      = "phase-data"
+	PRODUCTMOMENTTENSOR   = "moment-tensor"
+	PRODUCTFOCALMECHANISM = "focal-mechanism"
+	PRODUCTSHAKEMAP       = "shakemap"
+	PRODUCTDYFI           = "dyfi"
+	PRODUCTLOSSPAGER      = "losspager"
+	PRODUCTFINITEFAULT    = "finite-fault"
+)
+
+// productOrder lists the product types in the order they are output, before
+// any other product types.
+var productOrder = []string{
+	PRODUCTORIGIN,
+	PRODUCTPHASEDATA,
+	PRODUCTMOMENTTENSOR,
+	PRODUCTFOCALMECHANISM,
+	PRODUCTSHAKEMAP,
+	PRODUCTDYFI,
+	PRODUCTLOSSPAGER,
+	PRODUCTFINITEFAULT,
+}
+
+// Detail is an event detail document: the event summary together with every
+// product contributed for the event.
+type Detail struct {
+	Type  string           `json:"type"`
+	Props DetailProperties `json:"properties"`
+	Geo   Geometry         `json:"geometry"`
+	Id    string           `json:"id"`
+}
+
+type DetailProperties struct {
+	Properties
+	Products Products `json:"products"`
+}
+
+// Products maps a product type onto its products, the preferred one first.
+type Products map[string][]Product
+
+// Product is a version of a product of an event. Its Properties are strings
+// as served, read as typed values by OriginProperties, MechanismProperties and
+// the other accessors of each product type.
+type Product struct {
+	Id              string             `json:"id"`
+	Type            string             `json:"type"`
+	Code            string             `json:"code"`
+	Source          string             `json:"source"`
+	UpdateTime      int64              `json:"updateTime"`
+	Status          string             `json:"status"`
+	PreferredWeight int                `json:"preferredWeight"`
+	Properties      map[string]string  `json:"properties"`
+	Contents        map[string]Content `json:"contents"`
+}
+
+// Content is a file of a product, keyed by its path within the product.
+type Content struct {
+	ContentType  string `json:"contentType"`
+	LastModified int64  `json:"lastModified"`
+	Length       int64  `json:"length"`
+	URL          string `json:"url"`
+}
+
+// ExtractDetail unmarshals an event detail document.
+func ExtractDetail(res []byte) (*Detail, error) {
+	d := new(Detail)
+	err := json.Unmarshal(res, d)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// RequestDetail retrieves the detail document of an event.
+func RequestDetail(id string) (*Detail, error) {
+	endpoint, err := ExtractId("query", "json", id)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := RequestContent(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractDetail(content)
+}
+
+// Preferred returns the preferred product of a product type.
+func (p Products) Preferred(productType string) (*Product, error) {
+	if len(p[productType]) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrProductMissing, productType)
+	}
+	return &p[productType][0], nil
+}
+
+// Types returns the product types of the event, the common types first in a
+// fixed order followed by any others alphabetically.
+func (p Products) Types() []string {
+	types := make([]string, 0, len(p))
+	for productType := range p {
+		types = append(types, productType)
+	}
+
+	rank := func(productType string) int {
+		if i := slices.Index(productOrder, productType); i >= 0 {
+			return i
+		}
+		return len(productOrder)
+	}
+	slices.SortFunc(types, func(a, b string) int {
+		return cmp.Or(cmp.Compare(rank(a), rank(b)), cmp.Compare(a, b))
+	})
+	return types
+}
+
+// ContentPaths returns the paths of the product's content files in order.
+func (p *Product) ContentPaths() []string {
+	paths := make([]string, 0, len(p.Contents))
+	for path := range p.Contents {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// StdoutProducts outputs every product of an event with its source, update
+// time and content files.
+func StdoutProducts(d *Detail) {
+	if d == nil || len(d.Props.Products) == 0 {
+		fmt.Fprintf(os.Stdout, "No products found for the event.\n")
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "Event Products: %s\n--------------------\n", d.Id)
+	for _, productType := range d.Props.Products.Types() {
+		for _, p := range d.Props.Products[productType] {
+			updateTime := time.UnixMilli(p.UpdateTime).UTC().Format(time.DateTime)
+			fmt.Fprintf(os.Stdout, "%s (source: %s, code: %s, status: %s, updated: %s UTC)\n",
+				p.Type, p.Source, p.Code, p.Status, updateTime)
+			if summary := ProductSummary(&p); len(summary) != 0 {
+				fmt.Fprintf(os.Stdout, "  %s\n", summary)
+			}
+			for _, path := range p.ContentPaths() {
+				c := p.Contents[path]
+				if len(path) == 0 {
+					path = "(inline)"
+				}
+				fmt.Fprintf(os.Stdout, "  %-40s %10d bytes  %s\n", path, c.Length, c.ContentType)
+			}
+		}
+	}
+}
+
+// RequestFile retrieves a content file of the product by its path. A path the
+// product does not list returns ErrContentMissing.
+func (p *Product) RequestFile(path string) ([]byte, error) {
+	c, ok := p.Contents[path]
+	if !ok || len(c.URL) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrContentMissing, p.Type, path)
+	}
+	return RequestContent(c.URL)
+}
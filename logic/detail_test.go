@@ -0,0 +1,66 @@
+package logic
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const detailJSON = `{
+  "type": "Feature",
+  "id": "us7000abcd",
+  "properties": {
+    "mag": 6.4,
+    "place": "Offshore Honshu",
+    "types": "dyfi,losspager,moment-tensor,origin,phase-data,nearby-cities",
+    "products": {
+      "moment-tensor": [
+        {"id": "urn:usgs-product:us:moment-tensor:us_7000abcd_mww:1", "type": "moment-tensor", "code": "us_7000abcd_mww", "source": "us",
+         "updateTime": 1718452800000, "status": "UPDATE", "preferredWeight": 218,
+         "properties": {"derived-magnitude": "6.42", "derived-magnitude-type": "Mww"},
+         "contents": {"quakeml.xml": {"contentType": "application/xml", "lastModified": 1718452790000, "length": 4221, "url": "https://example.com/quakeml.xml"}}},
+        {"id": "urn:usgs-product:gcmt:moment-tensor:gcmt_abcd:1", "type": "moment-tensor", "code": "gcmt_abcd", "source": "gcmt",
+         "updateTime": 1718460000000, "status": "UPDATE", "preferredWeight": 1}
+      ],
+      "origin": [
+        {"id": "urn:usgs-product:us:origin:us7000abcd:1", "type": "origin", "code": "us7000abcd", "source": "us",
+         "updateTime": 1718452700000, "status": "UPDATE",
+         "contents": {"": {"contentType": "text/plain", "length": 0}, "quakeml.xml": {"contentType": "application/xml", "length": 9000}}}
+      ],
+      "nearby-cities": [{"type": "nearby-cities", "source": "us", "code": "us7000abcd"}],
+      "dyfi": [{"type": "dyfi", "source": "us", "code": "us7000abcd"}]
+    }
+  },
+  "geometry": {"type": "Point", "coordinates": [142.4, 38.3, 29]}
+}`
+
+func TestExtractDetail(t *testing.T) {
+	d, err := ExtractDetail([]byte(detailJSON))
+	if err != nil {
+		t.Fatalf("ExtractDetail() = %v", err)
+	}
+
+	if d.Id != "us7000abcd" || d.Props.Mag == nil || *d.Props.Mag != 6.4 || d.Geo.Coordinates[2] != 29 {
+		t.Errorf("ExtractDetail() summary = %s %v %v", d.Id, d.Props.Mag, d.Geo.Coordinates)
+	}
+
+	types := d.Props.Products.Types()
+	want := []string{PRODUCTORIGIN, PRODUCTMOMENTTENSOR, PRODUCTDYFI, "nearby-cities"}
+	if !slices.Equal(types, want) {
+		t.Errorf("Types() = %v; want %v", types, want)
+	}
+
+	mt, err := d.Props.Products.Preferred(PRODUCTMOMENTTENSOR)
+	if err != nil || mt.Source != "us" || mt.Properties["derived-magnitude"] != "6.42" || mt.Contents["quakeml.xml"].Length != 4221 {
+		t.Errorf("Preferred(moment-tensor) = %+v %v", mt, err)
+	}
+
+	origin, _ := d.Props.Products.Preferred(PRODUCTORIGIN)
+	if paths := origin.ContentPaths(); !slices.Equal(paths, []string{"", "quakeml.xml"}) {
+		t.Errorf("ContentPaths() = %q", paths)
+	}
+
+	if _, err := d.Props.Products.Preferred(PRODUCTSHAKEMAP); !errors.Is(err, ErrProductMissing) {
+		t.Errorf("Preferred(shakemap) = %v; want %v", err, ErrProductMissing)
+	}
+}
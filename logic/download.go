@@ -0,0 +1,145 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrFlagGlobOption = errors.New("--glob option invalid")
+var ErrContentSize = errors.New("downloaded size differs from the advertised size")
+
+// ContentFile is a content file of an event product selected for download.
+type ContentFile struct {
+	ProductType string
+	Path        string
+	Content
+}
+
+// SelectContents returns the content files of the preferred product of each
+// product type in productTypes, or of every product type when productTypes is
+// empty, whose path or file name matches glob. Inline contents, which have no
+// file, are left out.
+func SelectContents(d *Detail, productTypes []string, glob string) ([]ContentFile, error) {
+	if len(glob) == 0 {
+		glob = "*"
+	}
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, ErrFlagGlobOption
+	}
+
+	if len(productTypes) == 0 {
+		productTypes = d.Props.Products.Types()
+	}
+
+	var files []ContentFile
+	for _, productType := range productTypes {
+		p, err := d.Props.Products.Preferred(productType)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, contentPath := range p.ContentPaths() {
+			c := p.Contents[contentPath]
+			if len(contentPath) == 0 || len(c.URL) == 0 {
+				continue
+			}
+
+			fullMatch, _ := path.Match(glob, contentPath)
+			baseMatch, _ := path.Match(glob, path.Base(contentPath))
+			if fullMatch || baseMatch {
+				files = append(files, ContentFile{ProductType: productType, Path: contentPath, Content: c})
+			}
+		}
+	}
+	return files, nil
+}
+
+// Destination returns where a content file is saved within dir, as
+// dir/<product type>/<path>. Paths that would leave dir are rejected.
+func (f ContentFile) Destination(dir string) (string, error) {
+	rel := filepath.Join(f.ProductType, filepath.FromSlash(f.Path))
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("content path %q leaves the download directory", f.Path)
+	}
+	return filepath.Join(dir, rel), nil
+}
+
+// DownloadContent saves a content file to dest. The file is first written to
+// dest.part, and a partial file left behind by an earlier attempt is resumed
+// with a range request. The completed file must match the advertised size
+// before it is moved to dest. A dest already holding the advertised size is
+// left as is, and DownloadContent reports whether it downloaded anything.
+func DownloadContent(ctx context.Context, c Content, dest string) (bool, error) {
+	if info, err := os.Stat(dest); err == nil && info.Size() == c.Length {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return false, err
+	}
+
+	part := dest + ".part"
+	var offset int64
+	if info, err := os.Stat(part); err == nil && info.Size() < c.Length {
+		offset = info.Size()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	if offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the range, so start over.
+		flags |= os.O_TRUNC
+	default:
+		body, _ := io.ReadAll(response.Body)
+		return false, fmt.Errorf("%w: %s: %s", ErrRequestStatus, response.Status, strings.TrimSpace(string(body)))
+	}
+
+	file, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return false, err
+	}
+
+	written, err := io.Copy(file, response.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, err
+	}
+
+	size := written
+	if response.StatusCode == http.StatusPartialContent {
+		size += offset
+	}
+	if size != c.Length {
+		if size > c.Length {
+			os.Remove(part)
+		}
+		return false, fmt.Errorf("%w: %s has %d bytes, expected %d", ErrContentSize, dest, size, c.Length)
+	}
+
+	return true, os.Rename(part, dest)
+}
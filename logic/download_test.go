@@ -0,0 +1,116 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type SelectContentsTest struct {
+	types []string
+	glob  string
+	out   []string
+	err   error
+}
+
+func TestSelectContents(t *testing.T) {
+	d := &Detail{Props: DetailProperties{Products: Products{
+		PRODUCTSHAKEMAP: {{Source: "us", PreferredWeight: 10, Contents: map[string]Content{
+			"download/grid.xml":      {Length: 3, URL: "https://example.com/grid.xml"},
+			"download/intensity.jpg": {Length: 3, URL: "https://example.com/intensity.jpg"},
+			"":                       {Length: 0},
+		}}},
+		PRODUCTMOMENTTENSOR: {
+			{Source: "us", PreferredWeight: 20, Contents: map[string]Content{"quakeml.xml": {Length: 3, URL: "https://example.com/us.xml"}}},
+			{Source: "gcmt", PreferredWeight: 1, Contents: map[string]Content{"quakeml.xml": {Length: 3, URL: "https://example.com/gcmt.xml"}}},
+		},
+	}}}
+
+	sTests := []SelectContentsTest{
+		{nil, "", []string{"moment-tensor/quakeml.xml", "shakemap/download/grid.xml", "shakemap/download/intensity.jpg"}, nil},
+		{[]string{PRODUCTSHAKEMAP}, "*.xml", []string{"shakemap/download/grid.xml"}, nil},
+		{[]string{PRODUCTSHAKEMAP}, "download/*.jpg", []string{"shakemap/download/intensity.jpg"}, nil},
+		{[]string{PRODUCTMOMENTTENSOR}, "*.json", nil, nil},
+		{[]string{PRODUCTDYFI}, "", nil, ErrProductMissing},
+		{nil, "[", nil, ErrFlagGlobOption},
+	}
+
+	for _, test := range sTests {
+		files, err := SelectContents(d, test.types, test.glob)
+		var got []string
+		for _, f := range files {
+			got = append(got, f.ProductType+"/"+f.Path)
+		}
+		if !errors.Is(err, test.err) || strings.Join(got, " ") != strings.Join(test.out, " ") {
+			t.Errorf("SelectContents(%v, %q) = %v %v; want %v %v", test.types, test.glob, got, err, test.out, test.err)
+		}
+		if len(files) > 0 && files[0].ProductType == PRODUCTMOMENTTENSOR && files[0].URL != "https://example.com/us.xml" {
+			t.Errorf("SelectContents() picked %s; want the preferred product", files[0].URL)
+		}
+	}
+}
+
+func TestContentFileDestination(t *testing.T) {
+	f := ContentFile{ProductType: PRODUCTSHAKEMAP, Path: "download/grid.xml"}
+	if dest, err := f.Destination("out"); err != nil || dest != filepath.Join("out", "shakemap", "download", "grid.xml") {
+		t.Errorf("Destination() = %q %v", dest, err)
+	}
+
+	f.Path = "../../etc/passwd"
+	if _, err := f.Destination("out"); err == nil {
+		t.Errorf("Destination(%q) = nil; want an error", f.Path)
+	}
+}
+
+func TestDownloadContent(t *testing.T) {
+	const body = "0123456789"
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "grid.xml", timeNow(), strings.NewReader(body))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "shakemap", "grid.xml")
+	c := Content{Length: int64(len(body)), URL: server.URL}
+
+	// Resume from a partial file left by an interrupted download.
+	os.MkdirAll(filepath.Dir(dest), 0o755)
+	os.WriteFile(dest+".part", []byte(body[:4]), 0o644)
+
+	downloaded, err := DownloadContent(context.Background(), c, dest)
+	if err != nil || !downloaded {
+		t.Fatalf("DownloadContent() = %v %v", downloaded, err)
+	}
+	if content, _ := os.ReadFile(dest); string(content) != body {
+		t.Errorf("DownloadContent() wrote %q; want %q", content, body)
+	}
+	if _, err := os.Stat(dest + ".part"); err == nil {
+		t.Errorf("DownloadContent() left %s.part behind", dest)
+	}
+
+	// A complete file is not requested again.
+	downloaded, err = DownloadContent(context.Background(), c, dest)
+	if err != nil || downloaded {
+		t.Errorf("DownloadContent() of a complete file = %v %v", downloaded, err)
+	}
+	if want := fmt.Sprint([]string{"bytes=4-"}); fmt.Sprint(ranges) != want {
+		t.Errorf("DownloadContent() ranges = %v; want %v", ranges, want)
+	}
+
+	// A size mismatch is an error and the file is not completed.
+	other := filepath.Join(filepath.Dir(dest), "short.xml")
+	_, err = DownloadContent(context.Background(), Content{Length: 20, URL: server.URL}, other)
+	if !errors.Is(err, ErrContentSize) {
+		t.Errorf("DownloadContent() with a short body = %v; want %v", err, ErrContentSize)
+	}
+	if _, err := os.Stat(other); err == nil {
+		t.Errorf("DownloadContent() completed %s despite the size mismatch", other)
+	}
+}
@@ -0,0 +1,224 @@
+package logic
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrFlagDYFISourceOption = errors.New("--dyfi-source option invalid")
+var ErrFlagBinWidthOption = errors.New("--bin-width option invalid")
+
+// Content paths of the DYFI summaries, aggregated by geocoded cell or by ZIP
+// code.
+const (
+	DYFIGEOPATH = "cdi_geo.txt"
+	DYFIZIPPATH = "cdi_zip.txt"
+)
+
+// DYFIMAXBINS caps the number of distance bins, so a narrow --bin-width over
+// distant cells fails instead of allocating an enormous table.
+const DYFIMAXBINS = 1000
+
+// dyfiPaths maps a --dyfi-source value onto the summary file it reads.
+var dyfiPaths = map[string]string{
+	"geo": DYFIGEOPATH,
+	"zip": DYFIZIPPATH,
+}
+
+// DYFICell is the community intensity of a geocoded cell or ZIP code, with its
+// hypocentral distance in km.
+type DYFICell struct {
+	Location  string  `json:"location"`
+	CDI       float64 `json:"cdi"`
+	Responses int     `json:"responses"`
+	Distance  float64 `json:"distance"`
+	Lat       float64 `json:"latitude"`
+	Lon       float64 `json:"longitude"`
+	City      string  `json:"city,omitempty"`
+	State     string  `json:"state,omitempty"`
+}
+
+// DYFIBin aggregates the cells within a range of distances. MeanCDI is
+// weighted by the number of responses of each cell.
+type DYFIBin struct {
+	MinDistance float64 `json:"minDistance"`
+	MaxDistance float64 `json:"maxDistance"`
+	Cells       int     `json:"cells"`
+	Responses   int     `json:"responses"`
+	MeanCDI     float64 `json:"meanCdi"`
+	MaxCDI      float64 `json:"maxCdi"`
+}
+
+// RequestDYFI retrieves the cells of the DYFI summary of the event's preferred
+// dyfi product. source selects the geocoded ("geo") or ZIP code ("zip")
+// summary; an empty source prefers the geocoded one.
+func RequestDYFI(d *Detail, source string) ([]DYFICell, error) {
+	p, err := d.Props.Products.Preferred(PRODUCTDYFI)
+	if err != nil {
+		return nil, err
+	}
+
+	path, ok := dyfiPaths[source]
+	if len(source) == 0 {
+		path = DYFIGEOPATH
+		if _, geo := p.Contents[DYFIGEOPATH]; !geo {
+			path = DYFIZIPPATH
+		}
+	} else if !ok {
+		return nil, ErrFlagDYFISourceOption
+	}
+
+	content, err := p.RequestFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractDYFICells(content)
+}
+
+// ExtractDYFICells reads a DYFI summary: comma separated location, CDI, number
+// of responses, hypocentral distance, latitude, longitude, suspect flag, city
+// and state, with '#' comment lines. Cells flagged as suspect are left out.
+func ExtractDYFICells(content []byte) ([]DYFICell, error) {
+	r := csv.NewReader(strings.NewReader(string(content)))
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.LazyQuotes = true
+
+	var cells []DYFICell
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 6 {
+			return nil, fmt.Errorf("DYFI summary line %q has %d fields, expected at least 6", strings.Join(record, ","), len(record))
+		}
+
+		var numbers [5]float64
+		for i, field := range record[1:6] {
+			numbers[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, fmt.Errorf("DYFI summary field %q: %w", field, err)
+			}
+		}
+		if len(record) > 6 && strings.TrimSpace(record[6]) == "1" {
+			continue
+		}
+
+		c := DYFICell{
+			Location:  strings.TrimSpace(record[0]),
+			CDI:       numbers[0],
+			Responses: int(numbers[1]),
+			Distance:  numbers[2],
+			Lat:       numbers[3],
+			Lon:       numbers[4],
+		}
+		if len(record) > 7 {
+			c.City = strings.TrimSpace(record[7])
+		}
+		if len(record) > 8 {
+			c.State = strings.TrimSpace(record[8])
+		}
+		cells = append(cells, c)
+	}
+	return cells, nil
+}
+
+// BinDYFI aggregates cells into consecutive distance bins width km wide,
+// from the nearest bin holding a cell to the farthest. Empty bins in between
+// are kept so the distances stay evenly spaced. Widths that would need more
+// than DYFIMAXBINS bins are rejected.
+func BinDYFI(cells []DYFICell, width float64) ([]DYFIBin, error) {
+	if width <= 0 || math.IsInf(width, 0) || math.IsNaN(width) {
+		return nil, ErrFlagBinWidthOption
+	}
+	if len(cells) == 0 {
+		return nil, nil
+	}
+
+	nearest, farthest := math.Inf(1), math.Inf(-1)
+	for _, c := range cells {
+		nearest, farthest = min(nearest, c.Distance), max(farthest, c.Distance)
+	}
+	if math.Floor(farthest/width)-math.Floor(nearest/width) >= DYFIMAXBINS {
+		return nil, fmt.Errorf("%w: more than %d bins of %g km", ErrFlagBinWidthOption, DYFIMAXBINS, width)
+	}
+	first, last := int(nearest/width), int(farthest/width)
+
+	bins := make([]DYFIBin, last-first+1)
+	weighted := make([]float64, len(bins))
+	for i := range bins {
+		bins[i].MinDistance = float64(first+i) * width
+		bins[i].MaxDistance = float64(first+i+1) * width
+	}
+	for _, c := range cells {
+		i := int(c.Distance/width) - first
+		bins[i].Cells++
+		bins[i].Responses += c.Responses
+		bins[i].MaxCDI = max(bins[i].MaxCDI, c.CDI)
+		weighted[i] += c.CDI * float64(c.Responses)
+	}
+	for i := range bins {
+		if bins[i].Responses > 0 {
+			bins[i].MeanCDI = weighted[i] / float64(bins[i].Responses)
+		}
+	}
+	return bins, nil
+}
+
+// StdoutDYFI outputs the totals stated by the dyfi product followed by the
+// intensity of each distance bin.
+func StdoutDYFI(props DYFIProperties, bins []DYFIBin) {
+	if len(bins) == 0 {
+		fmt.Fprintf(os.Stdout, "No DYFI responses found for the event.\n")
+		return
+	}
+
+	if props.NumResponses != nil {
+		fmt.Fprintf(os.Stdout, "Total Responses: %d\n", *props.NumResponses)
+	}
+	if props.MaxMMI != nil {
+		fmt.Fprintf(os.Stdout, "Maximum CDI: %.1f\n", *props.MaxMMI)
+	}
+
+	fmt.Fprintf(os.Stdout, "%-15s %7s %10s %9s %8s\n", "Distance (km)", "Cells", "Responses", "Mean CDI", "Max CDI")
+	for _, b := range bins {
+		distance := fmt.Sprintf("%g-%g", b.MinDistance, b.MaxDistance)
+		if b.Cells == 0 {
+			fmt.Fprintf(os.Stdout, "%-15s %7d %10d %9s %8s\n", distance, 0, 0, "-", "-")
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "%-15s %7d %10d %9.1f %8.1f\n", distance, b.Cells, b.Responses, b.MeanCDI, b.MaxCDI)
+	}
+}
+
+// WriteDYFICSV writes one CSV row per cell, suitable for plotting intensity
+// against distance.
+func WriteDYFICSV(w io.Writer, cells []DYFICell) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"location", "cdi", "responses", "distance", "latitude", "longitude", "city", "state"})
+	for _, c := range cells {
+		cw.Write([]string{
+			c.Location,
+			formatFloat(c.CDI),
+			strconv.Itoa(c.Responses),
+			formatFloat(c.Distance),
+			formatFloat(c.Lat),
+			formatFloat(c.Lon),
+			c.City,
+			c.State,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
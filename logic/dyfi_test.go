@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const dyfiGeoTxt = `# Columns: Geocoded box, CDI, No. of responses, Hypocentral distance, Latitude, Longitude, Suspect?, City, State
+UTM:(10S 0565 4194 1000),5.4,12,8,37.87,-122.26,0,Berkeley,CA
+UTM:(10S 0553 4181 1000),4.1,3,14,37.77,-122.42,0,"San Francisco, Mission",CA
+UTM:(10S 0600 4100 1000),6.0,1,12,37.1,-121.9,1,Suspect,CA
+UTM:(10S 0700 4000 1000),2.0,5,61.5,36.2,-120.8,0,Coalinga,CA
+`
+
+func TestExtractDYFICells(t *testing.T) {
+	cells, err := ExtractDYFICells([]byte(dyfiGeoTxt))
+	if err != nil {
+		t.Fatalf("ExtractDYFICells() = %v", err)
+	}
+
+	if len(cells) != 3 {
+		t.Fatalf("ExtractDYFICells() = %d cells; want 3 without the suspect one", len(cells))
+	}
+	want := DYFICell{"UTM:(10S 0553 4181 1000)", 4.1, 3, 14, 37.77, -122.42, "San Francisco, Mission", "CA"}
+	if cells[1] != want {
+		t.Errorf("ExtractDYFICells()[1] = %+v; want %+v", cells[1], want)
+	}
+
+	if _, err := ExtractDYFICells([]byte("94720,4.1,12\n")); err == nil {
+		t.Errorf("ExtractDYFICells() of a short line = nil; want an error")
+	}
+	if _, err := ExtractDYFICells([]byte("94720,IV,12,35,37.87,-122.26\n")); err == nil {
+		t.Errorf("ExtractDYFICells() of a malformed CDI = nil; want an error")
+	}
+}
+
+func TestBinDYFI(t *testing.T) {
+	cells, _ := ExtractDYFICells([]byte(dyfiGeoTxt))
+
+	bins, err := BinDYFI(cells, 20)
+	if err != nil {
+		t.Fatalf("BinDYFI() = %v", err)
+	}
+	if len(bins) != 4 {
+		t.Fatalf("BinDYFI() = %d bins; want 4", len(bins))
+	}
+
+	near := bins[0]
+	if near.MinDistance != 0 || near.MaxDistance != 20 || near.Cells != 2 || near.Responses != 15 ||
+		near.MaxCDI != 5.4 || near.MeanCDI < 5.139 || near.MeanCDI > 5.141 {
+		t.Errorf("BinDYFI()[0] = %+v", near)
+	}
+	if bins[1].Cells != 0 || bins[2].Cells != 0 || bins[3].MinDistance != 60 || bins[3].MeanCDI != 2 {
+		t.Errorf("BinDYFI() = %+v", bins)
+	}
+
+	if _, err := BinDYFI(cells, 0); !errors.Is(err, ErrFlagBinWidthOption) {
+		t.Errorf("BinDYFI(0) = %v; want %v", err, ErrFlagBinWidthOption)
+	}
+	if _, err := BinDYFI(cells, 1e-9); !errors.Is(err, ErrFlagBinWidthOption) {
+		t.Errorf("BinDYFI(1e-9) = %v; want %v", err, ErrFlagBinWidthOption)
+	}
+	if bins, err := BinDYFI(cells, 0.07); err != nil || len(bins) > DYFIMAXBINS {
+		t.Errorf("BinDYFI(0.07) = %d bins, %v; want at most %d", len(bins), err, DYFIMAXBINS)
+	}
+}
+
+func TestWriteDYFICSV(t *testing.T) {
+	cells, _ := ExtractDYFICells([]byte(dyfiGeoTxt))
+
+	var b bytes.Buffer
+	if err := WriteDYFICSV(&b, cells[:2]); err != nil {
+		t.Fatalf("WriteDYFICSV() = %v", err)
+	}
+	want := "location,cdi,responses,distance,latitude,longitude,city,state\n" +
+		"UTM:(10S 0565 4194 1000),5.4,12,8,37.87,-122.26,Berkeley,CA\n" +
+		"UTM:(10S 0553 4181 1000),4.1,3,14,37.77,-122.42,\"San Francisco, Mission\",CA\n"
+	if b.String() != want {
+		t.Errorf("WriteDYFICSV() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestRequestDYFI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, DYFIZIPPATH) {
+			w.Write([]byte("94720,4.1,12,35,37.87,-122.26,0,Berkeley,CA\n"))
+			return
+		}
+		w.Write([]byte(dyfiGeoTxt))
+	}))
+	defer server.Close()
+
+	d := &Detail{Props: DetailProperties{Products: Products{PRODUCTDYFI: {{
+		Type:     PRODUCTDYFI,
+		Contents: map[string]Content{DYFIZIPPATH: {URL: server.URL + "/" + DYFIZIPPATH}},
+	}}}}}
+
+	// Without a geocoded summary the ZIP code summary is read.
+	cells, err := RequestDYFI(d, "")
+	if err != nil || len(cells) != 1 || cells[0].Location != "94720" {
+		t.Errorf("RequestDYFI(\"\") = %v %v", cells, err)
+	}
+	if _, err := RequestDYFI(d, "geo"); !errors.Is(err, ErrContentMissing) {
+		t.Errorf("RequestDYFI(geo) = %v; want %v", err, ErrContentMissing)
+	}
+	if _, err := RequestDYFI(d, "county"); !errors.Is(err, ErrFlagDYFISourceOption) {
+		t.Errorf("RequestDYFI(county) = %v; want %v", err, ErrFlagDYFISourceOption)
+	}
+
+	d.Props.Products[PRODUCTDYFI][0].Contents[DYFIGEOPATH] = Content{URL: server.URL + "/" + DYFIGEOPATH}
+	if cells, err := RequestDYFI(d, ""); err != nil || len(cells) != 3 {
+		t.Errorf("RequestDYFI(\"\") = %v %v; want the geocoded cells", cells, err)
+	}
+}
@@ -0,0 +1,497 @@
+package logic
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrFlagLocalFormat = errors.New("--output format option unavailable with local filters")
+var ErrFlagEventTypeOption = errors.New("--event-type option invalid")
+var ErrFlagReviewStatusOption = errors.New("--review-status option invalid")
+var ErrFlagAlertLevelOption = errors.New("--alert-level option invalid")
+var ErrFlagFeltOption = errors.New("--felt option invalid")
+var ErrFlagCdiOption = errors.New("--cdi option invalid")
+var ErrFlagMmiOption = errors.New("--mmi option invalid")
+var ErrFlagSigOption = errors.New("--sig option invalid")
+
+var reviewStatuses = []string{"automatic", "reviewed", "deleted"}
+var alertLevels = []string{"green", "yellow", "orange", "red"}
+
+// Filter reports whether a Feature should be kept in a set of events.
+type Filter func(f Feature) bool
+
+// FilterFlags holds the user input flag values shared by the realtime and
+// fdsn commands to narrow down a set of events.
+type FilterFlags struct {
+	BBox    string
+	Radius  string
+	Polygon string
+	Depth   string
+
+	EventType    string
+	ReviewStatus string
+	AlertLevel   string
+
+	Felt    string
+	Cdi     string
+	Mmi     string
+	Sig     string
+	Tsunami bool
+
+	// Places resolves named locations given in Radius.
+	Places map[string]Place
+
+	// Shape holds the parsed Polygon file once LoadPolygon has run.
+	Shape *Polygon
+}
+
+// LoadPolygon reads and parses the Polygon file into Shape so that the
+// request parameters and the local filters share a single parse.
+func (ff *FilterFlags) LoadPolygon() error {
+	if ff.Shape != nil {
+		return nil
+	}
+
+	polygon, err := ParsePolygonFile(ff.Polygon)
+	if err != nil {
+		return err
+	}
+	ff.Shape = polygon
+	return nil
+}
+
+// polygon returns Shape, parsing the Polygon file if it has not been loaded.
+func (ff FilterFlags) polygon() (*Polygon, error) {
+	if ff.Shape != nil {
+		return ff.Shape, nil
+	}
+	return ParsePolygonFile(ff.Polygon)
+}
+
+// FilterFeatures returns the Features that satisfy every filter.
+func FilterFeatures(features Features, filters ...Filter) Features {
+	if len(filters) == 0 {
+		return features
+	}
+
+	f := make(Features, 0, len(features))
+	for _, feature := range features {
+		keep := true
+		for _, filter := range filters {
+			if !filter(feature) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			f = append(f, feature)
+		}
+	}
+	return f
+}
+
+// ExtractFilters resolves the user input flag values into Filters that run
+// over decoded Features, such as those of a realtime feed that cannot be
+// filtered by the server.
+func ExtractFilters(ff FilterFlags) ([]Filter, error) {
+	var filters []Filter
+
+	bbox, err := ParseBBox(ff.BBox)
+	if err != nil {
+		return nil, err
+	}
+	if bbox != nil {
+		filters = append(filters, locationFilter(bbox.Contains))
+	}
+
+	circle, err := ParseCircle(ff.Radius, ff.Places)
+	if err != nil {
+		return nil, err
+	}
+	if circle != nil {
+		filters = append(filters, locationFilter(circle.Contains))
+	}
+
+	polygon, err := ff.polygon()
+	if err != nil {
+		return nil, err
+	}
+	if polygon != nil {
+		filters = append(filters, locationFilter(polygon.Contains))
+	}
+
+	minDepth, maxDepth, err := extractRange(ff.Depth, ErrFlagDepthOption)
+	if err != nil {
+		return nil, err
+	}
+	if len(minDepth) != 0 || len(maxDepth) != 0 {
+		filters = append(filters, rangeFilter(minDepth, maxDepth, depth))
+	}
+
+	eventTypes, err := extractList(ff.EventType, nil, ErrFlagEventTypeOption)
+	if err != nil {
+		return nil, err
+	}
+	if len(eventTypes) != 0 {
+		filters = append(filters, listFilter(eventTypes, eventType))
+	}
+
+	statuses, err := extractList(ff.ReviewStatus, reviewStatuses, ErrFlagReviewStatusOption)
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) != 0 {
+		filters = append(filters, listFilter(statuses, reviewStatus))
+	}
+
+	alerts, err := extractList(ff.AlertLevel, alertLevels, ErrFlagAlertLevelOption)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) != 0 {
+		filters = append(filters, listFilter(alerts, alertLevel))
+	}
+
+	impactFilters, err := ff.impactFilters()
+	if err != nil {
+		return nil, err
+	}
+	filters = append(filters, impactFilters...)
+
+	return filters, nil
+}
+
+// impactFilters resolves the felt report, intensity, significance and tsunami
+// flag values into Filters.
+func (ff FilterFlags) impactFilters() ([]Filter, error) {
+	var filters []Filter
+
+	ranges := []struct {
+		flag   string
+		isInt  bool
+		errOpt error
+		value  func(f Feature) (float64, bool)
+	}{
+		{ff.Felt, true, ErrFlagFeltOption, felt},
+		{ff.Cdi, false, ErrFlagCdiOption, cdi},
+		{ff.Mmi, false, ErrFlagMmiOption, mmi},
+		{ff.Sig, true, ErrFlagSigOption, sig},
+	}
+
+	for _, r := range ranges {
+		lower, upper, err := extractImpactRange(r.flag, r.isInt, r.errOpt)
+		if err != nil {
+			return nil, err
+		}
+		if len(lower) != 0 || len(upper) != 0 {
+			filters = append(filters, rangeFilter(lower, upper, r.value))
+		}
+	}
+
+	if ff.Tsunami {
+		filters = append(filters, tsunami)
+	}
+
+	return filters, nil
+}
+
+// setValues adds the FDSN parameters for the filters that the service can
+// apply itself.
+func (ff FilterFlags) setValues(v url.Values) error {
+	minDepth, maxDepth, err := extractRange(ff.Depth, ErrFlagDepthOption)
+	if err != nil {
+		return err
+	}
+
+	if len(minDepth) != 0 {
+		v.Set("mindepth", minDepth)
+	}
+
+	if len(maxDepth) != 0 {
+		v.Set("maxdepth", maxDepth)
+	}
+
+	bbox, err := ParseBBox(ff.BBox)
+	if err != nil {
+		return err
+	}
+
+	if bbox != nil {
+		bbox.setValues(v)
+	}
+
+	circle, err := ParseCircle(ff.Radius, ff.Places)
+	if err != nil {
+		return err
+	}
+
+	if circle != nil {
+		if bbox != nil {
+			return ErrFlagRegionOption
+		}
+		circle.setValues(v)
+	}
+
+	// A polygon is applied to the response locally, so only narrow the
+	// request down to its bounds when no other region was given.
+	polygon, err := ff.polygon()
+	if err != nil {
+		return err
+	}
+
+	if polygon != nil && bbox == nil && circle == nil {
+		polygon.Bounds().setValues(v)
+	}
+
+	eventTypes, err := extractList(ff.EventType, nil, ErrFlagEventTypeOption)
+	if err != nil {
+		return err
+	}
+
+	if len(eventTypes) != 0 {
+		v.Set("eventtype", strings.Join(eventTypes, ","))
+	}
+
+	// The service filters on a single review status and leaves deleted
+	// events out unless asked, so other selections are applied locally.
+	statuses, err := extractList(ff.ReviewStatus, reviewStatuses, ErrFlagReviewStatusOption)
+	if err != nil {
+		return err
+	}
+
+	if len(statuses) == 1 && statuses[0] != "deleted" {
+		v.Set("reviewstatus", statuses[0])
+	}
+
+	if slices.Contains(statuses, "deleted") {
+		v.Set("includedeleted", "true")
+	}
+
+	alerts, err := extractList(ff.AlertLevel, alertLevels, ErrFlagAlertLevelOption)
+	if err != nil {
+		return err
+	}
+
+	if len(alerts) == 1 {
+		v.Set("alertlevel", alerts[0])
+	}
+
+	// The service only takes a lower bound on felt reports and has no tsunami
+	// parameter, so those are applied locally.
+	minFelt, _, err := extractImpactRange(ff.Felt, true, ErrFlagFeltOption)
+	if err != nil {
+		return err
+	}
+
+	if len(minFelt) != 0 {
+		v.Set("minfelt", minFelt)
+	}
+
+	params := []struct {
+		flag, minParam, maxParam string
+		isInt                    bool
+		errOpt                   error
+	}{
+		{ff.Cdi, "mincdi", "maxcdi", false, ErrFlagCdiOption},
+		{ff.Mmi, "minmmi", "maxmmi", false, ErrFlagMmiOption},
+		{ff.Sig, "minsig", "maxsig", true, ErrFlagSigOption},
+	}
+
+	for _, p := range params {
+		lower, upper, err := extractImpactRange(p.flag, p.isInt, p.errOpt)
+		if err != nil {
+			return err
+		}
+
+		if len(lower) != 0 {
+			v.Set(p.minParam, lower)
+		}
+
+		if len(upper) != 0 {
+			v.Set(p.maxParam, upper)
+		}
+	}
+
+	return nil
+}
+
+// ExtractFDSNFilters resolves the user input flag values that the FDSN
+// service cannot apply into Filters that run over the decoded response.
+func ExtractFDSNFilters(flags FDSNFlags) ([]Filter, error) {
+	var filters []Filter
+
+	polygon, err := flags.polygon()
+	if err != nil {
+		return nil, err
+	}
+	if polygon != nil {
+		filters = append(filters, locationFilter(polygon.Contains))
+	}
+
+	statuses, err := extractList(flags.ReviewStatus, reviewStatuses, ErrFlagReviewStatusOption)
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) > 1 || slices.Contains(statuses, "deleted") {
+		filters = append(filters, listFilter(statuses, reviewStatus))
+	}
+
+	alerts, err := extractList(flags.AlertLevel, alertLevels, ErrFlagAlertLevelOption)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) > 1 {
+		filters = append(filters, listFilter(alerts, alertLevel))
+	}
+
+	_, maxFelt, err := extractImpactRange(flags.Felt, true, ErrFlagFeltOption)
+	if err != nil {
+		return nil, err
+	}
+	if len(maxFelt) != 0 {
+		filters = append(filters, rangeFilter("", maxFelt, felt))
+	}
+
+	if flags.Tsunami {
+		filters = append(filters, tsunami)
+	}
+
+	return filters, nil
+}
+
+// extractImpactRange parses a range flag value for one of the impact
+// measures, which are never negative and, when isInt, whole numbers.
+func extractImpactRange(iFlag string, isInt bool, errOpt error) (string, string, error) {
+	lower, upper, err := extractRange(iFlag, errOpt)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, bound := range []string{lower, upper} {
+		if len(bound) == 0 {
+			continue
+		}
+		if val, _ := strconv.ParseFloat(bound, 64); val < 0 {
+			return "", "", errOpt
+		}
+		if _, err := strconv.Atoi(bound); isInt && err != nil {
+			return "", "", errOpt
+		}
+	}
+	return lower, upper, nil
+}
+
+// extractList parses a comma separated flag value into lower case values.
+// When valid is not nil every value must be one of valid. errOpt is returned
+// for any malformed value.
+func extractList(lFlag string, valid []string, errOpt error) ([]string, error) {
+	if len(strings.TrimSpace(lFlag)) == 0 {
+		return nil, nil
+	}
+
+	var values []string
+	for _, field := range strings.Split(lFlag, ",") {
+		val := strings.ToLower(strings.TrimSpace(field))
+		if len(val) == 0 {
+			return nil, errOpt
+		}
+		if valid != nil && !slices.Contains(valid, val) {
+			return nil, errOpt
+		}
+		if !slices.Contains(values, val) {
+			values = append(values, val)
+		}
+	}
+	return values, nil
+}
+
+// listFilter keeps the Features whose field matches one of values regardless
+// of case.
+func listFilter(values []string, field func(f Feature) string) Filter {
+	return func(f Feature) bool {
+		return slices.Contains(values, strings.ToLower(field(f)))
+	}
+}
+
+func eventType(f Feature) string {
+	return f.Props.Type
+}
+
+func reviewStatus(f Feature) string {
+	return f.Props.Status
+}
+
+func alertLevel(f Feature) string {
+	return optionalf("%s", f.Props.Alert)
+}
+
+func felt(f Feature) (float64, bool) {
+	if f.Props.Felt == nil {
+		return 0, false
+	}
+	return float64(*f.Props.Felt), true
+}
+
+func cdi(f Feature) (float64, bool) {
+	if f.Props.Cdi == nil {
+		return 0, false
+	}
+	return *f.Props.Cdi, true
+}
+
+func mmi(f Feature) (float64, bool) {
+	if f.Props.Mmi == nil {
+		return 0, false
+	}
+	return *f.Props.Mmi, true
+}
+
+func sig(f Feature) (float64, bool) {
+	if f.Props.Sig == nil {
+		return 0, false
+	}
+	return float64(*f.Props.Sig), true
+}
+
+func tsunami(f Feature) bool {
+	return f.Props.Tsunami == 1
+}
+
+func locationFilter(contains func(lat, lon float64) bool) Filter {
+	return func(f Feature) bool {
+		if len(f.Geo.Coordinates) < 2 {
+			return false
+		}
+		return contains(f.Geo.Coordinates[1], f.Geo.Coordinates[0])
+	}
+}
+
+// rangeFilter keeps the Features whose value lies within the inclusive bounds
+// returned by extractRange. An empty bound is left open.
+func rangeFilter(lower, upper string, value func(f Feature) (float64, bool)) Filter {
+	low, lowErr := strconv.ParseFloat(lower, 64)
+	high, highErr := strconv.ParseFloat(upper, 64)
+	return func(f Feature) bool {
+		val, ok := value(f)
+		if !ok {
+			return false
+		}
+		if lowErr == nil && val < low {
+			return false
+		}
+		if highErr == nil && val > high {
+			return false
+		}
+		return true
+	}
+}
+
+func depth(f Feature) (float64, bool) {
+	if len(f.Geo.Coordinates) < 3 {
+		return 0, false
+	}
+	return f.Geo.Coordinates[2], true
+}
@@ -0,0 +1,92 @@
+package logic
+
+import (
+	"slices"
+	"testing"
+)
+
+// ptr returns a pointer to v for the optional properties of a Feature.
+func ptr[T any](v T) *T {
+	return &v
+}
+
+type FilterTest struct {
+	in  FilterFlags
+	out []string
+	err error
+}
+
+var filterFeatures = Features{
+	{Id: "us1", Props: Properties{Mag: ptr(6.1), Type: "earthquake", Status: "reviewed", Alert: ptr("orange"), Felt: ptr(120), Cdi: ptr(6.2), Mmi: ptr(7.1), Sig: ptr(950), Tsunami: 1}, Geo: Geometry{Coordinates: []float64{142.4, 38.3, 29}}},
+	{Id: "ci2", Props: Properties{Mag: ptr(2.3), Type: "quarry blast", Status: "reviewed", Felt: ptr(3), Cdi: ptr(2.7), Sig: ptr(81)}, Geo: Geometry{Coordinates: []float64{-117.6, 35.7, 8.2}}},
+	{Id: "nc3", Props: Properties{Mag: ptr(1.1), Type: "earthquake", Status: "automatic"}, Geo: Geometry{Coordinates: []float64{-122.8, 38.8, -1.2}}},
+	{Id: "us4", Props: Properties{Mag: ptr(5.4), Type: "earthquake", Status: "deleted", Alert: ptr("red"), Felt: ptr(12), Cdi: ptr(4.1), Mmi: ptr(5.6), Sig: ptr(460)}, Geo: Geometry{Coordinates: []float64{-178.2, -17.9, 560}}},
+}
+
+func TestExtractFilters(t *testing.T) {
+	fTests := []FilterTest{
+		{FilterFlags{}, []string{"us1", "ci2", "nc3", "us4"}, nil},
+		{FilterFlags{BBox: "30,45,-130,-110"}, []string{"ci2", "nc3"}, nil},
+		{FilterFlags{BBox: "-30,0,170,-170"}, []string{"us4"}, nil},
+		{FilterFlags{Radius: "38,-122,200km"}, []string{"nc3"}, nil},
+		{FilterFlags{Depth: "<10"}, []string{"ci2", "nc3"}, nil},
+		{FilterFlags{Depth: "0,70"}, []string{"us1", "ci2"}, nil},
+		{FilterFlags{Depth: ">300", BBox: "-90,90,-180,180"}, []string{"us4"}, nil},
+		{FilterFlags{Depth: "deep"}, nil, ErrFlagDepthOption},
+		{FilterFlags{EventType: "Quarry Blast, explosion"}, []string{"ci2"}, nil},
+		{FilterFlags{ReviewStatus: "reviewed"}, []string{"us1", "ci2"}, nil},
+		{FilterFlags{ReviewStatus: "automatic,deleted"}, []string{"nc3", "us4"}, nil},
+		{FilterFlags{AlertLevel: "orange,red"}, []string{"us1", "us4"}, nil},
+		{FilterFlags{AlertLevel: "red", ReviewStatus: "reviewed"}, nil, nil},
+		{FilterFlags{ReviewStatus: "pending"}, nil, ErrFlagReviewStatusOption},
+		{FilterFlags{Felt: ">10"}, []string{"us1", "us4"}, nil},
+		{FilterFlags{Felt: "1,20"}, []string{"ci2", "us4"}, nil},
+		{FilterFlags{Cdi: "<3"}, []string{"ci2"}, nil},
+		{FilterFlags{Mmi: ">5"}, []string{"us1", "us4"}, nil},
+		{FilterFlags{Sig: "100,600"}, []string{"us4"}, nil},
+		{FilterFlags{Tsunami: true}, []string{"us1"}, nil},
+		{FilterFlags{Felt: ">1.5"}, nil, ErrFlagFeltOption},
+		{FilterFlags{Cdi: "<-1"}, nil, ErrFlagCdiOption},
+		{FilterFlags{AlertLevel: "orange,"}, nil, ErrFlagAlertLevelOption},
+	}
+
+	for _, test := range fTests {
+		filters, err := ExtractFilters(test.in)
+		if err != test.err {
+			t.Errorf("ExtractFilters(%+v) = %v; want %v", test.in, err, test.err)
+			continue
+		}
+
+		var ids []string
+		if err == nil {
+			for _, f := range FilterFeatures(filterFeatures, filters...) {
+				ids = append(ids, f.Id)
+			}
+		}
+		if !slices.Equal(ids, test.out) {
+			t.Errorf("FilterFeatures(%+v) = %v; want %v", test.in, ids, test.out)
+		}
+	}
+}
+
+type FDSNFiltersTest struct {
+	in  FilterFlags
+	out int
+}
+
+func TestExtractFDSNFilters(t *testing.T) {
+	fTests := []FDSNFiltersTest{
+		{FilterFlags{EventType: "earthquake", ReviewStatus: "reviewed", AlertLevel: "red"}, 0},
+		{FilterFlags{ReviewStatus: "deleted"}, 1},
+		{FilterFlags{ReviewStatus: "automatic,reviewed", AlertLevel: "orange,red"}, 2},
+		{FilterFlags{Felt: ">10", Cdi: "3,6", Mmi: "<7", Sig: ">600"}, 0},
+		{FilterFlags{Felt: "10,100", Tsunami: true}, 2},
+	}
+
+	for _, test := range fTests {
+		filters, err := ExtractFDSNFilters(FDSNFlags{FilterFlags: test.in})
+		if len(filters) != test.out || err != nil {
+			t.Errorf("ExtractFDSNFilters(%+v) = %d filters %v; want %d filters", test.in, len(filters), err, test.out)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package logic
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"time"
 )
@@ -33,31 +34,31 @@ type Feature struct {
 type Features []Feature
 
 type Properties struct {
-	Mag     float64 `json:"mag"`
-	Place   string  `json:"place"`
-	Time    int64   `json:"time"`
-	Updated int64   `json:"updated"`
-	Tz      int     `json:"tz"`
-	Url     string  `json:"url"`
-	Detail  string  `json:"detail"`
-	Felt    int     `json:"felt"`
-	Cdi     float64 `json:"cdi"`
-	Mmi     float64 `json:"mmi"`
-	Alert   string  `json:"alert"`
-	Status  string  `json:"status"`
-	Tsunami int     `json:"tsunami"`
-	Sig     int     `json:"sig"`
-	Net     string  `json:"net"`
-	Code    string  `json:"code"`
-	Ids     string  `json:"ids"`
-	Sources string  `json:"sources"`
-	Types   string  `json:"types"`
-	Nst     int     `json:"nst"`
-	Dmin    float64 `json:"dmin"`
-	Rms     float64 `json:"rms"`
-	Gap     float64 `json:"gap"`
-	MagType string  `json:"magType"`
-	Type    string  `json:"type"`
+	Mag     *float64 `json:"mag"`
+	Place   string   `json:"place"`
+	Time    int64    `json:"time"`
+	Updated int64    `json:"updated"`
+	Tz      int      `json:"tz"`
+	Url     string   `json:"url"`
+	Detail  string   `json:"detail"`
+	Felt    *int     `json:"felt"`
+	Cdi     *float64 `json:"cdi"`
+	Mmi     *float64 `json:"mmi"`
+	Alert   *string  `json:"alert"`
+	Status  string   `json:"status"`
+	Tsunami int      `json:"tsunami"`
+	Sig     *int     `json:"sig"`
+	Net     string   `json:"net"`
+	Code    string   `json:"code"`
+	Ids     string   `json:"ids"`
+	Sources string   `json:"sources"`
+	Types   string   `json:"types"`
+	Nst     *int     `json:"nst"`
+	Dmin    *float64 `json:"dmin"`
+	Rms     *float64 `json:"rms"`
+	Gap     *float64 `json:"gap"`
+	MagType string   `json:"magType"`
+	Type    string   `json:"type"`
 }
 
 type Geometry struct {
@@ -81,6 +82,35 @@ func ExtractFeatures(res []byte) (Features, error) {
 	return f, nil
 }
 
+// ExtractResponse unmarshals a complete response so that its Features may be
+// transformed locally and serialized again.
+func ExtractResponse(res []byte) (*USGSResponse, error) {
+	usgsRes := new(USGSResponse)
+	err := json.Unmarshal(res, usgsRes)
+	if err != nil {
+		return nil, err
+	}
+	return usgsRes, nil
+}
+
+// WriteJSON serializes a response whose Features may have been transformed
+// locally, keeping the metadata count in step with the Features.
+func WriteJSON(w io.Writer, res *USGSResponse) error {
+	res.Meta.Count = len(res.Features)
+	return json.NewEncoder(w).Encode(res)
+}
+
+// WriteNDJSON serializes each Feature as a JSON object on a line of its own.
+func WriteNDJSON(w io.Writer, features Features) error {
+	encoder := json.NewEncoder(w)
+	for _, f := range features {
+		if err := encoder.Encode(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ExtractSingleFeature unmarshals one event into one Feature to prepare for
 // formatting.
 func ExtractSingleFeature(res []byte) (*Feature, error) {
@@ -101,12 +131,23 @@ func StdoutFeatures(features Features) {
 		return
 	}
 
+	StdoutFeaturesHeader()
+	StdoutFeatureRows(features)
+}
+
+// StdoutFeaturesHeader outputs the header of the StdoutFeatures table.
+func StdoutFeaturesHeader() {
 	fmt.Printf("%-10s %s %-4s %-42s %-5s %s\n", "EventId", "Date-Time UTC+00:00", "Mag", "Place", "Lat", "Long")
+}
+
+// StdoutFeatureRows outputs the rows of the StdoutFeatures table without its
+// header, for tables that grow as events arrive.
+func StdoutFeatureRows(features Features) {
 	for _, f := range features {
 		dateTimeVal := time.UnixMilli(f.Props.Time).UTC()
 		dateTimeStr := dateTimeVal.Format(time.DateTime)
-		fmt.Fprintf(os.Stdout, "%s %s %3.2f %-41s %6.2f %7.2f\n",
-			f.Id, dateTimeStr, f.Props.Mag, f.Props.Place, f.Geo.Coordinates[1], f.Geo.Coordinates[0])
+		fmt.Fprintf(os.Stdout, "%s %s %4s %-41s %6.2f %7.2f\n",
+			f.Id, dateTimeStr, optionalf("%3.2f", f.Props.Mag), f.Props.Place, f.Geo.Coordinates[1], f.Geo.Coordinates[0])
 	}
 }
 
@@ -154,24 +195,38 @@ func StdoutSingleEvent(f *Feature) {
 	fmt.Fprintf(os.Stdout, "Updated Time (UTC+00:00): %s\n", updateDateTimeStr)
 	fmt.Fprintf(os.Stdout, "Time Zone Offset: %d\n", f.Props.Tz)
 	fmt.Fprintf(os.Stdout, "Place: %s\n", f.Props.Place)
-	fmt.Fprintf(os.Stdout, "Magnitude: %3.2f\n", f.Props.Mag)
+	fmt.Fprintf(os.Stdout, "Magnitude: %s\n", optionalf("%3.2f", f.Props.Mag))
 	fmt.Fprintf(os.Stdout, "Magnitude Type: %s\n", resolveMagType(f.Props.MagType))
 	fmt.Fprintf(os.Stdout, "Depth: %.2f km\n", f.Geo.Coordinates[2])
 	fmt.Fprintf(os.Stdout, "Latitude: %.2f\n", f.Geo.Coordinates[1])
 	fmt.Fprintf(os.Stdout, "Longitude: %.2f\n", f.Geo.Coordinates[0])
-	fmt.Fprintf(os.Stdout, "Horizontal distance (in deg) from epicenter to the nearest station: %f\n", f.Props.Dmin)
-	fmt.Fprintf(os.Stdout, "Largest Azimuthal Gap between stations (deg): %.2f\n", f.Props.Gap)
-	fmt.Fprintf(os.Stdout, "Root-Mean-Square (RMS) Travel Time Residual (sec): %.3f\n", f.Props.Rms)
+	fmt.Fprintf(os.Stdout, "Horizontal distance (in deg) from epicenter to the nearest station: %s\n", optionalf("%f", f.Props.Dmin))
+	fmt.Fprintf(os.Stdout, "Largest Azimuthal Gap between stations (deg): %s\n", optionalf("%.2f", f.Props.Gap))
+	fmt.Fprintf(os.Stdout, "Root-Mean-Square (RMS) Travel Time Residual (sec): %s\n", optionalf("%.3f", f.Props.Rms))
 	fmt.Fprintf(os.Stdout, "Seismic Event Type: %s\n", f.Props.Type)
-	fmt.Fprintf(os.Stdout, "PAGER Alert Level: %s\n", f.Props.Alert)
-	fmt.Fprintf(os.Stdout, "Number of Felt Reports of DYFI: %d\n", f.Props.Felt)
-	fmt.Fprintf(os.Stdout, "Intensity Level: %.2f\n", f.Props.Cdi)
-	fmt.Fprintf(os.Stdout, "Modified Mercalli Intensity (MMI): %.2f\n", f.Props.Mmi)
-	fmt.Fprintf(os.Stdout, "Event Significance: %d\n", f.Props.Sig)
+	fmt.Fprintf(os.Stdout, "PAGER Alert Level: %s\n", optionalf("%s", f.Props.Alert))
+	fmt.Fprintf(os.Stdout, "Number of Felt Reports of DYFI: %s\n", optionalf("%d", f.Props.Felt))
+	fmt.Fprintf(os.Stdout, "Intensity Level: %s\n", optionalf("%.2f", f.Props.Cdi))
+	fmt.Fprintf(os.Stdout, "Modified Mercalli Intensity (MMI): %s\n", optionalf("%.2f", f.Props.Mmi))
+	fmt.Fprintf(os.Stdout, "Event Significance: %s\n", optionalf("%d", f.Props.Sig))
 	fmt.Fprintf(os.Stdout, "Large Event in Oceanic Region: %d\n", f.Props.Tsunami)
-	fmt.Fprintf(os.Stdout, "Number of Stations used to determine location: %d\n", f.Props.Nst)
+	fmt.Fprintf(os.Stdout, "Number of Stations used to determine location: %s\n", optionalf("%d", f.Props.Nst))
 	fmt.Fprintf(os.Stdout, "Associated Event Ids: %s\n", f.Props.Ids)
 	fmt.Fprintf(os.Stdout, "Network Contributors: %s\n", f.Props.Sources)
 	fmt.Fprintf(os.Stdout, "Preferred Contributor Id: %s\n", f.Props.Net)
 	fmt.Fprintf(os.Stdout, "Event Id Code: %s\n", f.Props.Code)
 }
+
+// optional formats the value v points at, or returns an empty string when the
+// value is absent from the response.
+func optional[T any](v *T, format func(T) string) string {
+	if v == nil {
+		return ""
+	}
+	return format(*v)
+}
+
+// optionalf is optional formatting the value with a fmt verb.
+func optionalf[T any](verb string, v *T) string {
+	return optional(v, func(v T) string { return fmt.Sprintf(verb, v) })
+}
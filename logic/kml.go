@@ -0,0 +1,265 @@
+package logic
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// KMLNAMESPACE is the namespace of a KML 2.2 document.
+const KMLNAMESPACE = "http://www.opengis.net/kml/2.2"
+
+// KMLICON is the icon of the placemarks of a KML document, and KMZICON the
+// path of the icon bundled within a KMZ archive.
+const (
+	KMLICON = "https://maps.google.com/mapfiles/kml/shapes/shaded_dot.png"
+	KMZICON = "files/dot.png"
+)
+
+// kmlMagnitudeClasses lists the lower magnitude bound and icon scale of each
+// magnitude class, the largest last.
+var kmlMagnitudeClasses = []struct {
+	min   float64
+	scale float64
+}{
+	{-10, 0.5},
+	{2.5, 0.8},
+	{4.5, 1.2},
+	{6, 1.8},
+}
+
+// kmlAgeClasses lists the upper age bound and icon color (aabbggrr) of each
+// age class, the most recent first.
+var kmlAgeClasses = []struct {
+	name   string
+	maxAge time.Duration
+	color  string
+}{
+	{"hour", time.Hour, "ff0000ff"},
+	{"day", 24 * time.Hour, "ff0080ff"},
+	{"week", 7 * 24 * time.Hour, "ff00ffff"},
+	{"older", -1, "ffffffff"},
+}
+
+type kmlDocument struct {
+	XMLName   xml.Name     `xml:"kml"`
+	Namespace string       `xml:"xmlns,attr"`
+	Document  kmlContainer `xml:"Document"`
+}
+
+type kmlContainer struct {
+	Name       string         `xml:"name"`
+	Styles     []kmlStyle     `xml:"Style"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlStyle struct {
+	Id   string       `xml:"id,attr"`
+	Icon kmlIconStyle `xml:"IconStyle"`
+}
+
+type kmlIconStyle struct {
+	Color string  `xml:"color"`
+	Scale float64 `xml:"scale"`
+	Href  string  `xml:"Icon>href"`
+}
+
+type kmlPlacemark struct {
+	Id          string   `xml:"id,attr"`
+	Name        string   `xml:"name"`
+	Description kmlCDATA `xml:"description"`
+	When        string   `xml:"TimeStamp>when"`
+	StyleUrl    string   `xml:"styleUrl"`
+	Coordinates string   `xml:"Point>coordinates"`
+}
+
+type kmlCDATA struct {
+	Text string `xml:",cdata"`
+}
+
+// WriteKML serializes Features as a KML document of placemarks. The icon of a
+// placemark grows with the magnitude and is colored by the age of the event,
+// and its balloon holds the details of the event.
+func WriteKML(w io.Writer, features Features) error {
+	return writeKML(w, features, KMLICON)
+}
+
+// WriteKMZ writes a KMZ archive holding the KML document of WriteKML together
+// with its icon, so that it displays without network access.
+func WriteKMZ(w io.Writer, features Features) error {
+	archive := zip.NewWriter(w)
+
+	doc, err := archive.Create("doc.kml")
+	if err != nil {
+		return err
+	}
+	if err := writeKML(doc, features, KMZICON); err != nil {
+		return err
+	}
+
+	icon, err := archive.Create(KMZICON)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(icon, dotIcon(64)); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func writeKML(w io.Writer, features Features, iconHref string) error {
+	doc := kmlDocument{
+		Namespace: KMLNAMESPACE,
+		Document: kmlContainer{
+			Name:       "geteq earthquakes",
+			Placemarks: make([]kmlPlacemark, 0, len(features)),
+		},
+	}
+
+	for i, m := range kmlMagnitudeClasses {
+		for j, a := range kmlAgeClasses {
+			style := kmlStyle{
+				Id:   kmlStyleId(i, j),
+				Icon: kmlIconStyle{Color: a.color, Scale: m.scale, Href: iconHref},
+			}
+			doc.Document.Styles = append(doc.Document.Styles, style)
+		}
+	}
+
+	now := timeNow()
+	for _, f := range features {
+		// A placemark needs a point, so events without a location are left out.
+		if len(f.Geo.Coordinates) < 2 {
+			continue
+		}
+		doc.Document.Placemarks = append(doc.Document.Placemarks, kmlFeature(f, now))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func kmlStyleId(magnitudeClass, ageClass int) string {
+	return fmt.Sprintf("m%d-%s", magnitudeClass, kmlAgeClasses[ageClass].name)
+}
+
+// kmlStyleClasses returns the magnitude and age classes of an event.
+func kmlStyleClasses(f Feature, now time.Time) (int, int) {
+	magnitudeClass := 0
+	for i, m := range kmlMagnitudeClasses {
+		if f.Props.Mag != nil && *f.Props.Mag >= m.min {
+			magnitudeClass = i
+		}
+	}
+
+	age := now.Sub(time.UnixMilli(f.Props.Time))
+	ageClass := len(kmlAgeClasses) - 1
+	for i, a := range kmlAgeClasses {
+		if a.maxAge > 0 && age < a.maxAge {
+			ageClass = i
+			break
+		}
+	}
+	return magnitudeClass, ageClass
+}
+
+func kmlFeature(f Feature, now time.Time) kmlPlacemark {
+	magnitudeClass, ageClass := kmlStyleClasses(f, now)
+
+	p := kmlPlacemark{
+		Id:          kmlPlacemarkId(f.Id),
+		Name:        f.Props.Place,
+		Description: kmlCDATA{kmlBalloon(f)},
+		When:        formatMillis(f.Props.Time),
+		StyleUrl:    "#" + kmlStyleId(magnitudeClass, ageClass),
+	}
+	if f.Props.Mag != nil {
+		p.Name = fmt.Sprintf("M %.1f - %s", *f.Props.Mag, f.Props.Place)
+	}
+	if len(f.Geo.Coordinates) >= 2 {
+		p.Coordinates = formatFloat(f.Geo.Coordinates[0]) + "," + formatFloat(f.Geo.Coordinates[1])
+	}
+	return p
+}
+
+// kmlPlacemarkId turns an eventid into an XML ID, which must start with a
+// letter and may only hold letters, digits, '.', '-' and '_'.
+func kmlPlacemarkId(id string) string {
+	return "event-" + strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(".-_", r)) {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// kmlBalloon returns the HTML table of event details shown in the balloon of
+// a placemark.
+func kmlBalloon(f Feature) string {
+	rows := [][2]string{
+		{"Event Id", f.Id},
+		{"Time (UTC)", time.UnixMilli(f.Props.Time).UTC().Format(time.DateTime)},
+		{"Place", f.Props.Place},
+		{"Magnitude", strings.TrimSpace(optionalf("%.2f", f.Props.Mag) + " " + f.Props.MagType)},
+		{"Depth", coordinate(f, 2) + " km"},
+		{"Latitude", coordinate(f, 1)},
+		{"Longitude", coordinate(f, 0)},
+		{"Review Status", f.Props.Status},
+		{"Seismic Event Type", f.Props.Type},
+		{"PAGER Alert Level", optionalf("%s", f.Props.Alert)},
+		{"Felt Reports", optionalf("%d", f.Props.Felt)},
+		{"Intensity (CDI)", optionalf("%.1f", f.Props.Cdi)},
+		{"MMI", optionalf("%.1f", f.Props.Mmi)},
+		{"Significance", optionalf("%d", f.Props.Sig)},
+		{"Tsunami Flag", fmt.Sprint(f.Props.Tsunami)},
+		{"Network", f.Props.Net},
+		{"Updated (UTC)", time.UnixMilli(f.Props.Updated).UTC().Format(time.DateTime)},
+	}
+
+	var b strings.Builder
+	b.WriteString("<table>")
+	for _, row := range rows {
+		fmt.Fprintf(&b, "<tr><th align=\"left\">%s</th><td>%s</td></tr>", row[0], html.EscapeString(row[1]))
+	}
+	b.WriteString("</table>")
+	if len(f.Props.Url) != 0 {
+		fmt.Fprintf(&b, "<p><a href=\"%s\">Event page</a></p>", html.EscapeString(f.Props.Url))
+	}
+	return b.String()
+}
+
+// dotIcon draws a white disc with a dark outline, which KML styles tint.
+func dotIcon(size int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	center := float64(size-1) / 2
+	radius := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)-center, float64(y)-center
+			switch d := dx*dx + dy*dy; {
+			case d <= (radius-4)*(radius-4):
+				img.Set(x, y, color.White)
+			case d <= radius*radius:
+				img.Set(x, y, color.NRGBA{0x33, 0x33, 0x33, 0xff})
+			}
+		}
+	}
+	return img
+}
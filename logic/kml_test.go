@@ -0,0 +1,136 @@
+package logic
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type kmlTestDocument struct {
+	Styles []struct {
+		Id    string  `xml:"id,attr"`
+		Color string  `xml:"IconStyle>color"`
+		Scale float64 `xml:"IconStyle>scale"`
+		Href  string  `xml:"IconStyle>Icon>href"`
+	} `xml:"Document>Style"`
+	Placemarks []struct {
+		Id          string `xml:"id,attr"`
+		Name        string `xml:"name"`
+		Description string `xml:"description"`
+		When        string `xml:"TimeStamp>when"`
+		StyleUrl    string `xml:"styleUrl"`
+		Coordinates string `xml:"Point>coordinates"`
+	} `xml:"Document>Placemark"`
+}
+
+func kmlTestFeatures() Features {
+	now := timeNow()
+	return Features{
+		{
+			Id:    "ci40012345",
+			Props: Properties{Mag: ptr(3.2), Place: `5 km NNE of Ridgecrest <CA> & "Trona"`, Time: now.Add(-30 * time.Minute).UnixMilli(), Url: "https://example.com/ci40012345?a=1&b=2"},
+			Geo:   Geometry{Coordinates: []float64{-117.6, 35.7, 8.2}},
+		},
+		{
+			Id:    "us7000abcd",
+			Props: Properties{Mag: ptr(6.4), Place: "Offshore Honshu", Time: now.Add(-72 * time.Hour).UnixMilli()},
+			Geo:   Geometry{Coordinates: []float64{142.4, 38.3, 29}},
+		},
+		{
+			Id:    "ak0241",
+			Props: Properties{Mag: ptr(-0.4), Place: "Alaska", Time: now.Add(-40 * 24 * time.Hour).UnixMilli()},
+			Geo:   Geometry{Coordinates: []float64{-150.1, 61.2, 30}},
+		},
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time { return time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC) }
+
+	var b bytes.Buffer
+	if err := WriteKML(&b, kmlTestFeatures()); err != nil {
+		t.Fatalf("WriteKML() = %v", err)
+	}
+
+	var doc kmlTestDocument
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("WriteKML() wrote invalid XML: %v\n%s", err, b.String())
+	}
+	if len(doc.Styles) != len(kmlMagnitudeClasses)*len(kmlAgeClasses) || doc.Styles[0].Href != KMLICON {
+		t.Errorf("WriteKML() styles = %+v", doc.Styles)
+	}
+	if len(doc.Placemarks) != 3 {
+		t.Fatalf("WriteKML() = %d placemarks; want 3", len(doc.Placemarks))
+	}
+
+	p := doc.Placemarks[0]
+	if p.Id != "event-ci40012345" || p.StyleUrl != "#m1-hour" || p.Coordinates != "-117.6,35.7" ||
+		p.When != "2024-06-15T11:30:00.000Z" || p.Name != `M 3.2 - 5 km NNE of Ridgecrest <CA> & "Trona"` {
+		t.Errorf("WriteKML() placemark = %+v", p)
+	}
+	if !strings.Contains(p.Description, "<td>5 km NNE of Ridgecrest &lt;CA&gt; &amp; &#34;Trona&#34;</td>") ||
+		!strings.Contains(p.Description, `<a href="https://example.com/ci40012345?a=1&amp;b=2">`) {
+		t.Errorf("WriteKML() balloon = %s", p.Description)
+	}
+
+	if doc.Placemarks[1].StyleUrl != "#m3-week" || doc.Placemarks[2].StyleUrl != "#m0-older" {
+		t.Errorf("WriteKML() styles = %s %s", doc.Placemarks[1].StyleUrl, doc.Placemarks[2].StyleUrl)
+	}
+}
+
+func TestWriteKMLWithoutLocation(t *testing.T) {
+	features := kmlTestFeatures()
+	features[1].Id = "2024 quake:1"
+	features[2].Geo.Coordinates = nil
+
+	var b bytes.Buffer
+	if err := WriteKML(&b, features); err != nil {
+		t.Fatalf("WriteKML() = %v", err)
+	}
+	var doc kmlTestDocument
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("WriteKML() wrote invalid XML: %v\n%s", err, b.String())
+	}
+	if len(doc.Placemarks) != 2 {
+		t.Fatalf("WriteKML() = %d placemarks; want 2 without the unlocated event", len(doc.Placemarks))
+	}
+	if id := doc.Placemarks[1].Id; id != "event-2024_quake_1" {
+		t.Errorf("WriteKML() placemark id = %q; want %q", id, "event-2024_quake_1")
+	}
+	if strings.Contains(b.String(), "<Point></Point>") {
+		t.Errorf("WriteKML() wrote an empty point:\n%s", b.String())
+	}
+}
+
+func TestWriteKMZ(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteKMZ(&b, kmlTestFeatures()); err != nil {
+		t.Fatalf("WriteKMZ() = %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatalf("WriteKMZ() wrote an invalid archive: %v", err)
+	}
+	if len(archive.File) != 2 || archive.File[0].Name != "doc.kml" || archive.File[1].Name != KMZICON {
+		t.Fatalf("WriteKMZ() files = %v", archive.File)
+	}
+
+	kml, _ := archive.File[0].Open()
+	content, _ := io.ReadAll(kml)
+	var doc kmlTestDocument
+	if err := xml.Unmarshal(content, &doc); err != nil || len(doc.Placemarks) != 3 || doc.Styles[0].Href != KMZICON {
+		t.Errorf("WriteKMZ() doc.kml = %v %+v", err, doc.Styles)
+	}
+
+	icon, _ := archive.File[1].Open()
+	if img, err := png.Decode(icon); err != nil || img.Bounds().Dx() != 64 {
+		t.Errorf("WriteKMZ() icon = %v", err)
+	}
+}
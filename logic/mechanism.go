@@ -0,0 +1,267 @@
+package logic
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// NodalPlane is a fault plane solution in degrees.
+type NodalPlane struct {
+	Strike float64 `json:"strike"`
+	Dip    float64 `json:"dip"`
+	Rake   float64 `json:"rake"`
+}
+
+// Axis is a principal axis of the moment tensor in degrees, with its
+// eigenvalue in N-m when known.
+type Axis struct {
+	Azimuth float64 `json:"azimuth"`
+	Plunge  float64 `json:"plunge"`
+	Value   float64 `json:"value,omitempty"`
+}
+
+// Tensor holds the moment tensor components in N-m in up-south-east
+// coordinates.
+type Tensor struct {
+	Mrr float64 `json:"mrr"`
+	Mtt float64 `json:"mtt"`
+	Mpp float64 `json:"mpp"`
+	Mrt float64 `json:"mrt"`
+	Mrp float64 `json:"mrp"`
+	Mtp float64 `json:"mtp"`
+}
+
+// Mechanism is the source mechanism of an event read from its moment-tensor
+// or focal-mechanism product. Fields the product does not provide are left
+// zero.
+type Mechanism struct {
+	ProductType         string       `json:"productType"`
+	Source              string       `json:"source"`
+	ScalarMoment        float64      `json:"scalarMoment,omitempty"`
+	Mw                  float64      `json:"mw,omitempty"`
+	MagnitudeType       string       `json:"magnitudeType,omitempty"`
+	PercentDoubleCouple float64      `json:"percentDoubleCouple,omitempty"`
+	NodalPlanes         []NodalPlane `json:"nodalPlanes,omitempty"`
+	T                   *Axis        `json:"tAxis,omitempty"`
+	N                   *Axis        `json:"nAxis,omitempty"`
+	P                   *Axis        `json:"pAxis,omitempty"`
+	Tensor              *Tensor      `json:"tensor,omitempty"`
+}
+
+// Mechanism returns the source mechanism of the preferred moment-tensor
+// product, or of the preferred focal-mechanism product when the event has no
+// moment tensor.
+func (d *Detail) Mechanism() (*Mechanism, error) {
+	p, err := d.Props.Products.Preferred(PRODUCTMOMENTTENSOR)
+	if err != nil {
+		p, err = d.Props.Products.Preferred(PRODUCTFOCALMECHANISM)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s or %s", ErrProductMissing, PRODUCTMOMENTTENSOR, PRODUCTFOCALMECHANISM)
+	}
+	return ExtractMechanism(p), nil
+}
+
+// ExtractMechanism reads the source mechanism from the properties of a
+// moment-tensor or focal-mechanism product. Mw is derived from the scalar
+// moment when the product does not state it.
+func ExtractMechanism(p *Product) *Mechanism {
+	props := p.MechanismProperties()
+	m := &Mechanism{
+		ProductType:   p.Type,
+		Source:        p.Source,
+		MagnitudeType: props.DerivedMagnitudeType,
+		NodalPlanes:   props.NodalPlanes,
+		T:             props.T,
+		N:             props.N,
+		P:             props.P,
+		Tensor:        props.Tensor,
+	}
+	if props.ScalarMoment != nil {
+		m.ScalarMoment = *props.ScalarMoment
+	}
+	if props.DerivedMagnitude != nil {
+		m.Mw = *props.DerivedMagnitude
+	}
+	if m.Mw == 0 && m.ScalarMoment > 0 {
+		m.Mw = momentMagnitude(m.ScalarMoment)
+		m.MagnitudeType = "Mw"
+	}
+
+	if dc := props.PercentDoubleCouple; dc != nil {
+		// Products state the double couple either as a fraction or a percent.
+		m.PercentDoubleCouple = *dc
+		if *dc <= 1 {
+			m.PercentDoubleCouple *= 100
+		}
+	}
+
+	return m
+}
+
+// momentMagnitude converts a scalar moment in N-m to Mw.
+func momentMagnitude(m0 float64) float64 {
+	return 2.0 / 3.0 * (math.Log10(m0) - 9.1)
+}
+
+// DoubleCoupleTensor returns the unit moment tensor of a pure double couple on
+// the nodal plane (Aki & Richards), in up-south-east coordinates.
+func DoubleCoupleTensor(np NodalPlane) Tensor {
+	toRad := math.Pi / 180
+	strike, dip, rake := np.Strike*toRad, np.Dip*toRad, np.Rake*toRad
+	sinS, cosS := math.Sincos(strike)
+	sinD, cosD := math.Sincos(dip)
+	sinR, cosR := math.Sincos(rake)
+	sin2S, cos2S := math.Sincos(2 * strike)
+	sin2D, cos2D := math.Sincos(2 * dip)
+
+	// North-east-down components.
+	mxx := -(sinD*cosR*sin2S + sin2D*sinR*sinS*sinS)
+	myy := sinD*cosR*sin2S - sin2D*sinR*cosS*cosS
+	mzz := sin2D * sinR
+	mxy := sinD*cosR*cos2S + 0.5*sin2D*sinR*sin2S
+	mxz := -(cosD*cosR*cosS + cos2D*sinR*sinS)
+	myz := -(cosD*cosR*sinS - cos2D*sinR*cosS)
+
+	return Tensor{Mrr: mzz, Mtt: mxx, Mpp: myy, Mrt: mxz, Mrp: -myz, Mtp: -mxy}
+}
+
+// radiation returns the P-wave polarity factor of the tensor for a ray leaving
+// the source along the north-east-down direction (n, e, d); it is positive for
+// compressional first motions.
+func (t Tensor) radiation(n, e, d float64) float64 {
+	mxx, myy, mzz := t.Mtt, t.Mpp, t.Mrr
+	mxy, mxz, myz := -t.Mtp, t.Mrt, -t.Mrp
+	return n*n*mxx + e*e*myy + d*d*mzz + 2*(n*e*mxy+n*d*mxz+e*d*myz)
+}
+
+// Beachball draws the focal sphere in a lower-hemisphere equal-area
+// projection with north up, using the moment tensor or else the first nodal
+// plane. Compressional quadrants are drawn with '#', dilatational ones with
+// '-', and the T and P axes are marked when known. The ball is radius lines
+// high on either side of its center and twice as wide to make up for the
+// aspect ratio of terminal cells. Beachball returns nil when the mechanism has
+// neither a tensor nor a nodal plane.
+func (m *Mechanism) Beachball(radius int) []string {
+	var t Tensor
+	switch {
+	case m.Tensor != nil:
+		t = *m.Tensor
+	case len(m.NodalPlanes) > 0:
+		t = DoubleCoupleTensor(m.NodalPlanes[0])
+	default:
+		return nil
+	}
+
+	rows, cols := 2*radius+1, 4*radius+1
+	grid := make([][]byte, rows)
+	for i := range grid {
+		grid[i] = make([]byte, cols)
+		y := float64(radius-i) / float64(radius)
+		for j := range grid[i] {
+			x := float64(j-2*radius) / float64(2*radius)
+			r := math.Hypot(x, y)
+			if r > 1 {
+				grid[i][j] = ' '
+				continue
+			}
+
+			// Invert the equal-area projection r = sqrt(2) sin(theta/2) for the
+			// angle theta of the ray from straight down.
+			theta := 2 * math.Asin(r/math.Sqrt2)
+			azimuth := math.Atan2(x, y)
+			sinTheta, cosTheta := math.Sincos(theta)
+			n, e := sinTheta*math.Cos(azimuth), sinTheta*math.Sin(azimuth)
+
+			grid[i][j] = '-'
+			if t.radiation(n, e, cosTheta) > 0 {
+				grid[i][j] = '#'
+			}
+		}
+	}
+
+	for _, axis := range []struct {
+		axis *Axis
+		mark byte
+	}{{m.T, 'T'}, {m.P, 'P'}} {
+		if axis.axis == nil {
+			continue
+		}
+		x, y := projectAxis(*axis.axis)
+		i := radius - int(math.Round(y*float64(radius)))
+		j := 2*radius + int(math.Round(x*float64(2*radius)))
+		grid[i][j] = axis.mark
+	}
+
+	lines := make([]string, rows)
+	for i, row := range grid {
+		lines[i] = strings.TrimRight(string(row), " ")
+	}
+	return lines
+}
+
+// projectAxis returns the position of an axis within the unit circle of the
+// lower-hemisphere equal-area projection, flipping upward axes down.
+func projectAxis(a Axis) (float64, float64) {
+	azimuth, plunge := a.Azimuth, a.Plunge
+	if plunge < 0 {
+		azimuth, plunge = azimuth+180, -plunge
+	}
+	toRad := math.Pi / 180
+	r := math.Sqrt2 * math.Sin((90-plunge)*toRad/2)
+	return r * math.Sin(azimuth*toRad), r * math.Cos(azimuth*toRad)
+}
+
+// StdoutMechanism outputs the source mechanism of an event followed by its
+// beachball.
+func StdoutMechanism(m *Mechanism) {
+	if m == nil {
+		fmt.Fprintf(os.Stdout, "No source mechanism found for the event.\n")
+		return
+	}
+
+	title := "Moment Tensor"
+	if m.ProductType == PRODUCTFOCALMECHANISM {
+		title = "Focal Mechanism"
+	}
+	fmt.Fprintf(os.Stdout, "%s (source: %s)\n--------------------\n", title, m.Source)
+	if m.ScalarMoment > 0 {
+		fmt.Fprintf(os.Stdout, "Scalar Moment (N-m): %.3e\n", m.ScalarMoment)
+	}
+	if m.Mw > 0 {
+		fmt.Fprintf(os.Stdout, "Moment Magnitude (%s): %.2f\n", cmp.Or(m.MagnitudeType, "Mw"), m.Mw)
+	}
+	if m.PercentDoubleCouple > 0 {
+		fmt.Fprintf(os.Stdout, "Percent Double Couple: %.0f%%\n", m.PercentDoubleCouple)
+	}
+	for i, np := range m.NodalPlanes {
+		fmt.Fprintf(os.Stdout, "Nodal Plane %d (strike/dip/rake): %.0f/%.0f/%.0f\n", i+1, np.Strike, np.Dip, np.Rake)
+	}
+	for _, axis := range []struct {
+		name string
+		axis *Axis
+	}{{"T", m.T}, {"N", m.N}, {"P", m.P}} {
+		if axis.axis == nil {
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "%s Axis (azimuth/plunge): %.0f/%.0f", axis.name, axis.axis.Azimuth, axis.axis.Plunge)
+		if axis.axis.Value != 0 {
+			fmt.Fprintf(os.Stdout, ", value (N-m): %.3e", axis.axis.Value)
+		}
+		fmt.Fprintln(os.Stdout)
+	}
+	if t := m.Tensor; t != nil {
+		fmt.Fprintf(os.Stdout, "Tensor (N-m): Mrr %.3e, Mtt %.3e, Mpp %.3e, Mrt %.3e, Mrp %.3e, Mtp %.3e\n",
+			t.Mrr, t.Mtt, t.Mpp, t.Mrt, t.Mrp, t.Mtp)
+	}
+
+	if lines := m.Beachball(8); lines != nil {
+		fmt.Fprintln(os.Stdout)
+		for _, line := range lines {
+			fmt.Fprintf(os.Stdout, "  %s\n", line)
+		}
+	}
+}
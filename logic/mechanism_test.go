@@ -0,0 +1,132 @@
+package logic
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExtractMechanism(t *testing.T) {
+	p := &Product{Type: PRODUCTMOMENTTENSOR, Source: "us", Properties: map[string]string{
+		"scalar-moment":         "5.125e+18",
+		"percent-double-couple": "0.9500",
+		"nodal-plane-1-strike":  "199.41",
+		"nodal-plane-1-dip":     "19.12",
+		"nodal-plane-1-rake":    "79.1",
+		"nodal-plane-2-strike":  "30.28",
+		"nodal-plane-2-dip":     "71.2",
+		"nodal-plane-2-slip":    "93.6",
+		"t-axis-azimuth":        "292",
+		"t-axis-plunge":         "64",
+		"t-axis-length":         "5.2e+18",
+		"p-axis-azimuth":        "118",
+		"p-axis-plunge":         "26",
+		"tensor-mrr":            "4.1e+18",
+		"tensor-mtt":            "-1.5e+18",
+		"tensor-mpp":            "-2.6e+18",
+		"tensor-mrt":            "2.2e+18",
+		"tensor-mrp":            "-2.4e+18",
+		"tensor-mtp":            "1.1e+18",
+	}}
+
+	m := ExtractMechanism(p)
+	if m.ScalarMoment != 5.125e18 || math.Abs(m.Mw-6.407) > 0.001 || m.MagnitudeType != "Mw" || m.PercentDoubleCouple != 95 {
+		t.Errorf("ExtractMechanism() moment = %v %v %q %v", m.ScalarMoment, m.Mw, m.MagnitudeType, m.PercentDoubleCouple)
+	}
+	if len(m.NodalPlanes) != 2 || m.NodalPlanes[1] != (NodalPlane{30.28, 71.2, 93.6}) {
+		t.Errorf("ExtractMechanism() nodal planes = %v", m.NodalPlanes)
+	}
+	if m.T == nil || *m.T != (Axis{292, 64, 5.2e18}) || m.N != nil || m.P == nil || m.P.Azimuth != 118 {
+		t.Errorf("ExtractMechanism() axes = %v %v %v", m.T, m.N, m.P)
+	}
+	if m.Tensor == nil || m.Tensor.Mrp != -2.4e18 {
+		t.Errorf("ExtractMechanism() tensor = %v", m.Tensor)
+	}
+
+	p = &Product{Type: PRODUCTFOCALMECHANISM, Properties: map[string]string{
+		"derived-magnitude":      "6.42",
+		"derived-magnitude-type": "Mww",
+		"nodal-plane-1-strike":   "199",
+		"nodal-plane-1-dip":      "19",
+		"tensor-mrr":             "4.1e+18",
+	}}
+	m = ExtractMechanism(p)
+	if m.Mw != 6.42 || m.MagnitudeType != "Mww" || m.NodalPlanes != nil || m.Tensor != nil {
+		t.Errorf("ExtractMechanism() of a partial product = %+v", m)
+	}
+}
+
+type DoubleCoupleTest struct {
+	in  NodalPlane
+	out Tensor
+}
+
+func TestDoubleCoupleTensor(t *testing.T) {
+	dTests := []DoubleCoupleTest{
+		// A thrust on a plane dipping 45 degrees east: vertical tension and
+		// east-west compression.
+		{NodalPlane{0, 45, 90}, Tensor{Mrr: 1, Mpp: -1}},
+		// Left-lateral strike-slip on a vertical north-south plane.
+		{NodalPlane{0, 90, 0}, Tensor{Mtp: -1}},
+		// A normal fault is the thrust reversed.
+		{NodalPlane{0, 45, -90}, Tensor{Mrr: -1, Mpp: 1}},
+	}
+
+	for _, test := range dTests {
+		got := DoubleCoupleTensor(test.in)
+		components := [][2]float64{
+			{got.Mrr, test.out.Mrr}, {got.Mtt, test.out.Mtt}, {got.Mpp, test.out.Mpp},
+			{got.Mrt, test.out.Mrt}, {got.Mrp, test.out.Mrp}, {got.Mtp, test.out.Mtp},
+		}
+		for _, c := range components {
+			if math.Abs(c[0]-c[1]) > 1e-9 {
+				t.Errorf("DoubleCoupleTensor(%v) = %+v; want %+v", test.in, got, test.out)
+				break
+			}
+		}
+	}
+}
+
+type BeachballTest struct {
+	row, col int
+	out      byte
+}
+
+func TestBeachball(t *testing.T) {
+	const radius = 6
+	center := 2 * radius
+
+	// Strike-slip on a north-south plane: compression in the northeast and
+	// southwest quadrants.
+	m := &Mechanism{NodalPlanes: []NodalPlane{{0, 90, 0}}}
+	lines := m.Beachball(radius)
+	if len(lines) != 2*radius+1 {
+		t.Fatalf("Beachball(%d) = %d lines; want %d", radius, len(lines), 2*radius+1)
+	}
+
+	bTests := []BeachballTest{
+		{radius - 3, center + 6, '#'},
+		{radius + 3, center - 6, '#'},
+		{radius - 3, center - 6, '-'},
+		{radius + 3, center + 6, '-'},
+	}
+	for _, test := range bTests {
+		if got := lines[test.row][test.col]; got != test.out {
+			t.Errorf("Beachball() strike-slip[%d][%d] = %q; want %q\n%s", test.row, test.col, got, test.out, lines)
+		}
+	}
+
+	// A thrust fills the center and marks its axes.
+	m = &Mechanism{
+		Tensor: &Tensor{Mrr: 1, Mpp: -1},
+		T:      &Axis{Azimuth: 0, Plunge: 90},
+		P:      &Axis{Azimuth: 90, Plunge: 0},
+	}
+	lines = m.Beachball(radius)
+	if lines[radius][center] != 'T' || lines[radius][2*center] != 'P' || lines[radius][center-3] != '#' || lines[radius][1] != '-' {
+		t.Errorf("Beachball() thrust =\n%s", lines)
+	}
+
+	if lines := (&Mechanism{}).Beachball(radius); lines != nil {
+		t.Errorf("Beachball() without a tensor or nodal plane = %q; want nil", lines)
+	}
+}
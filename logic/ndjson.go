@@ -0,0 +1,90 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+)
+
+// FlatColumns lists the columns every flat record starts with, and
+// FlatDefaultColumns the properties that follow unless others are selected.
+var (
+	FlatColumns        = []string{"id", "time", "latitude", "longitude", "depth"}
+	FlatDefaultColumns = []string{"mag", "place"}
+)
+
+// nullableColumns lists the text columns that are absent from some events,
+// whose empty values are written as JSON null.
+var nullableColumns = map[string]bool{
+	"alert": true, "locationSource": true, "magSource": true,
+}
+
+// numericColumns lists the columns whose values are written as JSON numbers.
+var numericColumns = map[string]bool{
+	"latitude": true, "longitude": true, "depth": true, "mag": true,
+	"nst": true, "gap": true, "dmin": true, "rms": true, "tz": true,
+	"felt": true, "cdi": true, "mmi": true, "tsunami": true, "sig": true,
+	"horizontalError": true, "depthError": true, "magError": true, "magNst": true,
+}
+
+// WriteFlatNDJSON serializes each Feature as a flat JSON object on a line of
+// its own, holding FlatColumns followed by the selected columns of the
+// format, or FlatDefaultColumns when none were selected. Times are formatted
+// as in CSV records, and are numbers when formatted as unix or millis. Values
+// an event does not have are null.
+func WriteFlatNDJSON(w io.Writer, features Features, format RecordFormat) error {
+	columns := slices.Clone(FlatColumns)
+	selected := format.Columns
+	if len(selected) == 0 {
+		selected = FlatDefaultColumns
+	}
+	for _, column := range selected {
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+
+	var line bytes.Buffer
+	for _, f := range features {
+		line.Reset()
+		line.WriteByte('{')
+		for i, column := range columns {
+			if i > 0 {
+				line.WriteByte(',')
+			}
+			key, _ := json.Marshal(column)
+			value, err := json.Marshal(format.flatValue(column, f))
+			if err != nil {
+				return err
+			}
+			line.Write(key)
+			line.WriteByte(':')
+			line.Write(value)
+		}
+		line.WriteString("}\n")
+
+		if _, err := w.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flatValue returns the value of a Feature in a column, typed for JSON.
+func (c RecordFormat) flatValue(column string, f Feature) any {
+	value := csvFields[column](f, c)
+
+	if nullableColumns[column] && len(value) == 0 {
+		return nil
+	}
+
+	isTime := column == "time" || column == "updated"
+	if numericColumns[column] || (isTime && (c.TimeFormat == "unix" || c.TimeFormat == "millis")) {
+		if len(value) == 0 {
+			return nil
+		}
+		return json.Number(value)
+	}
+	return value
+}
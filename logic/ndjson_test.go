@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+)
+
+type FlatNDJSONTest struct {
+	in  OutputFlags
+	out string
+}
+
+func TestWriteFlatNDJSON(t *testing.T) {
+	features := Features{
+		{
+			Id:    "ci40012345",
+			Props: Properties{Mag: ptr(3.2), Place: `5 km NNE of "Ridgecrest", CA`, Time: 1718452800123, Felt: ptr(12), Alert: ptr("green")},
+			Geo:   Geometry{Coordinates: []float64{-117.6, 35.7, 8.2}},
+		},
+		{
+			Id:    "ak0241",
+			Props: Properties{Mag: ptr(1.1), Place: "Alaska", Time: 1718452900000},
+			Geo:   Geometry{Coordinates: []float64{-150.1, 61.2}},
+		},
+	}
+
+	fTests := []FlatNDJSONTest{
+		{OutputFlags{Flatten: true},
+			`{"id":"ci40012345","time":"2024-06-15T12:00:00.123Z","latitude":35.7,"longitude":-117.6,"depth":8.2,"mag":3.2,"place":"5 km NNE of \"Ridgecrest\", CA"}` + "\n" +
+				`{"id":"ak0241","time":"2024-06-15T12:01:40.000Z","latitude":61.2,"longitude":-150.1,"depth":null,"mag":1.1,"place":"Alaska"}` + "\n"},
+		{OutputFlags{Columns: "felt,alert,id", TimeFormat: "millis", Flatten: true},
+			`{"id":"ci40012345","time":1718452800123,"latitude":35.7,"longitude":-117.6,"depth":8.2,"felt":12,"alert":"green"}` + "\n" +
+				`{"id":"ak0241","time":1718452900000,"latitude":61.2,"longitude":-150.1,"depth":null,"felt":null,"alert":null}` + "\n"},
+	}
+
+	for _, test := range fTests {
+		format, err := ExtractRecordFormat(test.in)
+		if err != nil || !format.Flatten {
+			t.Fatalf("ExtractRecordFormat(%+v) = %+v %v", test.in, format, err)
+		}
+
+		var b strings.Builder
+		if err := WriteFlatNDJSON(&b, features, format); err != nil {
+			t.Fatalf("WriteFlatNDJSON() = %v", err)
+		}
+		if b.String() != test.out {
+			t.Errorf("WriteFlatNDJSON(%+v) =\n%s\nwant\n%s", test.in, b.String(), test.out)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrRuleOption = errors.New("notification rule invalid")
+
+// NOTIFYTIMEOUT bounds the time a Notifier may take to deliver an event.
+const NOTIFYTIMEOUT = 30 * time.Second
+
+// ALERTQUEUELENGTH is the number of events a sink holds while it delivers
+// earlier ones, after which further events for the sink are dropped.
+const ALERTQUEUELENGTH = 256
+
+// Notifier delivers an event to a sink outside of geteq.
+type Notifier interface {
+	Notify(ctx context.Context, f Feature) error
+}
+
+// ExecNotifier runs a local command with the event as JSON on its standard
+// input.
+type ExecNotifier struct {
+	Command []string
+}
+
+// WebhookNotifier POSTs the event as JSON to a URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// FileNotifier appends the event as a line of JSON to a file.
+type FileNotifier struct {
+	Path string
+}
+
+func (n *ExecNotifier) Notify(ctx context.Context, f Feature) error {
+	if len(n.Command) == 0 {
+		return ErrRuleOption
+	}
+
+	payload, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, NOTIFYTIMEOUT)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, n.Command[0], n.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	return cmd.Run()
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, f Feature) error {
+	payload, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: NOTIFYTIMEOUT}
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", ErrRequestStatus, response.Status)
+	}
+	return nil
+}
+
+func (n *FileNotifier) Notify(ctx context.Context, f Feature) error {
+	file, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(file).Encode(f); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Rule is a notification rule from the configuration file. An event matching
+// every criterion that is set is delivered to every sink that is set.
+type Rule struct {
+	Name string `json:"name"`
+
+	Magnitude  string `json:"magnitude"`
+	BBox       string `json:"bbox"`
+	Radius     string `json:"radius"`
+	Polygon    string `json:"polygon"`
+	AlertLevel string `json:"alertLevel"`
+
+	Exec    []string `json:"exec"`
+	Webhook string   `json:"webhook"`
+	File    string   `json:"file"`
+}
+
+// Filters resolves the criteria of the rule into Filters, using the same
+// vocabulary as the command line flags.
+func (r Rule) Filters(places map[string]Place) ([]Filter, error) {
+	filters, err := ExtractFilters(FilterFlags{
+		BBox:       r.BBox,
+		Radius:     r.Radius,
+		Polygon:    r.Polygon,
+		AlertLevel: r.AlertLevel,
+		Places:     places,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	minMag, maxMag, err := extractMagnitudeRange(r.Magnitude)
+	if err != nil {
+		return nil, err
+	}
+	if len(minMag) != 0 || len(maxMag) != 0 {
+		filters = append(filters, rangeFilter(minMag, maxMag, magnitude))
+	}
+
+	return filters, nil
+}
+
+// extractMagnitudeRange parses a magnitude in the grammar of the fdsn
+// --magnitude flag, such as ">4.5", "4.0-6.0" or "4,6", checking that both
+// bounds are numbers as they are compared locally.
+func extractMagnitudeRange(mFlag string) (string, string, error) {
+	lower, upper, err := extractMagnitude(strings.TrimSpace(mFlag))
+	if err != nil {
+		return "", "", err
+	}
+	for _, bound := range []string{lower, upper} {
+		if _, err := strconv.ParseFloat(bound, 64); len(bound) != 0 && err != nil {
+			return "", "", ErrFlagMagOption
+		}
+	}
+	return lower, upper, nil
+}
+
+// Notifiers returns the sinks configured for the rule.
+func (r Rule) Notifiers() []Notifier {
+	var notifiers []Notifier
+	if len(r.Exec) != 0 {
+		notifiers = append(notifiers, &ExecNotifier{Command: r.Exec})
+	}
+	if len(r.Webhook) != 0 {
+		notifiers = append(notifiers, &WebhookNotifier{URL: r.Webhook})
+	}
+	if len(r.File) != 0 {
+		notifiers = append(notifiers, &FileNotifier{Path: r.File})
+	}
+	return notifiers
+}
+
+// Alerter delivers events to the sinks of every rule they match. Each sink
+// delivers its events in order on a goroutine of its own, so that a slow sink
+// neither holds up the others nor the caller.
+type Alerter struct {
+	rules []alertRule
+
+	start   sync.Once
+	workers sync.WaitGroup
+	logMu   sync.Mutex
+}
+
+type alertRule struct {
+	name    string
+	filters []Filter
+	sinks   []*alertSink
+}
+
+// alertSink queues the events of a rule for one of its notifiers.
+type alertSink struct {
+	notifier Notifier
+	queue    chan Feature
+}
+
+// NewAlerter resolves the criteria and sinks of rules. A rule without any
+// sink is invalid.
+func NewAlerter(rules []Rule, places map[string]Place) (*Alerter, error) {
+	a := new(Alerter)
+	for i, r := range rules {
+		name := r.Name
+		if len(name) == 0 {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		filters, err := r.Filters(places)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+
+		notifiers := r.Notifiers()
+		if len(notifiers) == 0 {
+			return nil, fmt.Errorf("rule %s: %w: no exec, webhook or file", name, ErrRuleOption)
+		}
+
+		var sinks []*alertSink
+		for _, n := range notifiers {
+			sinks = append(sinks, &alertSink{notifier: n, queue: make(chan Feature, ALERTQUEUELENGTH)})
+		}
+		a.rules = append(a.rules, alertRule{name: name, filters: filters, sinks: sinks})
+	}
+	return a, nil
+}
+
+// Alert queues each Feature for the sinks of every rule it matches and returns
+// without waiting for them. The sinks deliver until ctx is done; a sink that
+// fails, or whose queue is full, is reported to errLog without stopping the
+// others. Close waits for the queued events to be delivered.
+func (a *Alerter) Alert(ctx context.Context, features Features, errLog io.Writer) {
+	a.start.Do(func() {
+		for _, r := range a.rules {
+			for _, s := range r.sinks {
+				a.workers.Add(1)
+				go a.deliver(ctx, r.name, s, errLog)
+			}
+		}
+	})
+
+	for _, r := range a.rules {
+		for _, f := range FilterFeatures(features, r.filters...) {
+			for _, s := range r.sinks {
+				select {
+				case s.queue <- f:
+				default:
+					a.report(errLog, "Error: rule %s: event %s: %d notifications pending, event dropped\n", r.name, f.Id, len(s.queue))
+				}
+			}
+		}
+	}
+}
+
+// Close stops accepting events and waits for the sinks to deliver the queued
+// ones. Alert must not be called after Close.
+func (a *Alerter) Close() {
+	a.start.Do(func() {})
+	for _, r := range a.rules {
+		for _, s := range r.sinks {
+			close(s.queue)
+		}
+	}
+	a.workers.Wait()
+}
+
+func (a *Alerter) deliver(ctx context.Context, rule string, s *alertSink, errLog io.Writer) {
+	defer a.workers.Done()
+	for f := range s.queue {
+		if err := s.notifier.Notify(ctx, f); err != nil {
+			a.report(errLog, "Error: rule %s: event %s: %v\n", rule, f.Id, err)
+		}
+	}
+}
+
+func (a *Alerter) report(errLog io.Writer, format string, args ...any) {
+	a.logMu.Lock()
+	defer a.logMu.Unlock()
+	fmt.Fprintf(errLog, format, args...)
+}
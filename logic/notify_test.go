@@ -0,0 +1,189 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var notifyFeature = Feature{
+	Id:    "us7000abcd",
+	Props: Properties{Mag: ptr(6.4), Alert: ptr("orange"), Place: "Offshore Honshu"},
+	Geo:   Geometry{Coordinates: []float64{142.4, 38.3, 29}},
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got Feature
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	n := &WebhookNotifier{URL: server.URL}
+	if err := n.Notify(context.Background(), notifyFeature); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	if got.Id != notifyFeature.Id || contentType != "application/json" {
+		t.Errorf("webhook received %q %q; want %q application/json", got.Id, contentType, notifyFeature.Id)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	n = &WebhookNotifier{URL: failing.URL}
+	if err := n.Notify(context.Background(), notifyFeature); err == nil {
+		t.Errorf("Notify() to failing webhook = nil; want error")
+	}
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	n := &FileNotifier{Path: path}
+	for i := 0; i < 2; i++ {
+		if err := n.Notify(context.Background(), notifyFeature); err != nil {
+			t.Fatalf("Notify() = %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], notifyFeature.Id) {
+		t.Errorf("file notifier wrote %q; want 2 lines of the event", content)
+	}
+}
+
+func TestExecNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stdin.json")
+	n := &ExecNotifier{Command: []string{"sh", "-c", `cat > "$0"`, path}}
+	if err := n.Notify(context.Background(), notifyFeature); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+
+	var got Feature
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(content, &got); err != nil || got.Id != notifyFeature.Id {
+		t.Errorf("exec notifier stdin = %q %v; want the event", content, err)
+	}
+
+	n = &ExecNotifier{Command: []string{"sh", "-c", "exit 3"}}
+	if err := n.Notify(context.Background(), notifyFeature); err == nil {
+		t.Errorf("Notify() with failing command = nil; want error")
+	}
+}
+
+func TestAlerter(t *testing.T) {
+	dir := t.TempDir()
+	rules := []Rule{
+		{Name: "japan", BBox: "30,46,128,146", Magnitude: ">6", File: filepath.Join(dir, "japan")},
+		{Name: "moderate", Magnitude: "4.0-6.0", File: filepath.Join(dir, "moderate")},
+		{Name: "strong", Magnitude: "6.0-7.0", File: filepath.Join(dir, "strong")},
+		{Name: "red", AlertLevel: "red", File: filepath.Join(dir, "red")},
+		{Name: "near", Radius: "sendai,300km", File: filepath.Join(dir, "near")},
+	}
+	places := map[string]Place{"sendai": {Lat: 38.27, Lon: 140.87}}
+
+	a, err := NewAlerter(rules, places)
+	if err != nil {
+		t.Fatalf("NewAlerter() = %v", err)
+	}
+	a.Alert(context.Background(), Features{notifyFeature}, io.Discard)
+	a.Close()
+
+	for name, want := range map[string]bool{"japan": true, "moderate": false, "strong": true, "red": false, "near": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if (err == nil) != want {
+			t.Errorf("rule %s notified = %v; want %v", name, err == nil, want)
+		}
+	}
+
+	if _, err := NewAlerter([]Rule{{Name: "silent", Magnitude: ">5"}}, nil); err == nil {
+		t.Errorf("NewAlerter() without a sink = nil; want error")
+	}
+	if _, err := NewAlerter([]Rule{{Magnitude: "big", File: "x"}}, nil); err == nil {
+		t.Errorf("NewAlerter() with a bad magnitude = nil; want error")
+	}
+}
+
+// blockingNotifier delivers an event only once release is closed.
+type blockingNotifier struct {
+	release   chan struct{}
+	delivered chan string
+}
+
+func (n *blockingNotifier) Notify(ctx context.Context, f Feature) error {
+	<-n.release
+	n.delivered <- f.Id
+	return nil
+}
+
+func TestAlerterDoesNotBlock(t *testing.T) {
+	n := &blockingNotifier{release: make(chan struct{}), delivered: make(chan string, 2)}
+	a := &Alerter{rules: []alertRule{{name: "slow", sinks: []*alertSink{{notifier: n, queue: make(chan Feature, 1)}}}}}
+
+	done := make(chan struct{})
+	go func() {
+		a.Alert(context.Background(), Features{{Id: "a"}}, io.Discard)
+		a.Alert(context.Background(), Features{{Id: "b"}}, io.Discard)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Alert() blocked on a slow notifier")
+	}
+
+	close(n.release)
+	a.Close()
+	close(n.delivered)
+	var ids []string
+	for id := range n.delivered {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 || ids[0] != "a" {
+		t.Errorf("delivered = %v; want a first", ids)
+	}
+}
+
+type MagnitudeRangeTest struct {
+	in           string
+	lower, upper string
+	err          error
+}
+
+func TestExtractMagnitudeRange(t *testing.T) {
+	mTests := []MagnitudeRangeTest{
+		{">4.5", "4.5", "", nil},
+		{"<3", "", "3", nil},
+		{"4.0-6.0", "4.0", "6.0", nil},
+		{"4,6", "4", "6", nil},
+		{"5", "5", "5", nil},
+		{"", "", "", nil},
+		{"big", "", "", ErrFlagMagOption},
+		{">4..5", "", "", ErrFlagMagOption},
+	}
+
+	for _, test := range mTests {
+		lower, upper, err := extractMagnitudeRange(test.in)
+		if lower != test.lower || upper != test.upper || err != test.err {
+			t.Errorf("extractMagnitudeRange(%q) = %q %q %v; want %q %q %v", test.in, lower, upper, err, test.lower, test.upper, test.err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package logic
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Content paths of the losspager product.
+const (
+	PAGERALERTSPATH    = "json/alerts.json"
+	PAGEREXPOSURESPATH = "json/exposures.json"
+	PAGERCITIESPATH    = "json/cities.json"
+)
+
+// PAGERMAXCITIES is the number of most exposed cities kept from the product.
+const PAGERMAXCITIES = 10
+
+// Pager is the PAGER loss estimate of an event. AlertLevel is the overall
+// alert level and MaxMMI the strongest estimated shaking, as stated by the
+// product.
+type Pager struct {
+	Source     string          `json:"source"`
+	AlertLevel string          `json:"alertLevel,omitempty"`
+	MaxMMI     *float64        `json:"maxMmi"`
+	Fatality   LossAlert       `json:"fatality"`
+	Economic   LossAlert       `json:"economic"`
+	Exposure   []PagerExposure `json:"exposure"`
+	Cities     []PagerCity     `json:"cities"`
+}
+
+// LossAlert is the alert level of a loss estimate with the probability of each
+// range of losses.
+type LossAlert struct {
+	Level string    `json:"level"`
+	Units string    `json:"units"`
+	Bins  []LossBin `json:"bins"`
+}
+
+type LossBin struct {
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	Probability float64 `json:"probability"`
+	Color       string  `json:"color"`
+}
+
+// PagerExposure is the estimated population exposed to an MMI level.
+type PagerExposure struct {
+	MMI        int   `json:"mmi"`
+	Population int64 `json:"population"`
+}
+
+type PagerCity struct {
+	Name       string  `json:"name"`
+	Country    string  `json:"ccode"`
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	Population int64   `json:"pop"`
+	MMI        float64 `json:"mmi"`
+}
+
+type pagerAlerts struct {
+	Fatality LossAlert `json:"fatality"`
+	Economic LossAlert `json:"economic"`
+}
+
+type pagerExposures struct {
+	Population struct {
+		MMI        []int     `json:"mmi"`
+		Aggregated []float64 `json:"aggregated_exposure"`
+	} `json:"population_exposure"`
+}
+
+// RequestPager retrieves the alerts, exposures and cities of the event's
+// preferred losspager product.
+func RequestPager(d *Detail) (*Pager, error) {
+	p, err := d.Props.Products.Preferred(PRODUCTLOSSPAGER)
+	if err != nil {
+		return nil, err
+	}
+
+	var files [3][]byte
+	for i, path := range []string{PAGERALERTSPATH, PAGEREXPOSURESPATH, PAGERCITIESPATH} {
+		files[i], err = p.RequestFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pager, err := ExtractPager(files[0], files[1], files[2])
+	if err != nil {
+		return nil, err
+	}
+	props := p.PagerProperties()
+	pager.Source = p.Source
+	pager.AlertLevel = props.AlertLevel
+	pager.MaxMMI = props.MaxMMI
+	return pager, nil
+}
+
+// ExtractPager unmarshals the alerts, exposures and cities files of a
+// losspager product. Only the PAGERMAXCITIES cities exposed to the strongest
+// shaking are kept, the most populous first among equals.
+func ExtractPager(alerts, exposures, cities []byte) (*Pager, error) {
+	var a pagerAlerts
+	if err := json.Unmarshal(alerts, &a); err != nil {
+		return nil, err
+	}
+
+	var e pagerExposures
+	if err := json.Unmarshal(exposures, &e); err != nil {
+		return nil, err
+	}
+
+	pager := &Pager{Fatality: a.Fatality, Economic: a.Economic}
+	if err := json.Unmarshal(cities, &pager.Cities); err != nil {
+		return nil, err
+	}
+
+	for i, population := range e.Population.Aggregated {
+		mmi := i + 1
+		if i < len(e.Population.MMI) {
+			mmi = e.Population.MMI[i]
+		}
+		pager.Exposure = append(pager.Exposure, PagerExposure{mmi, int64(population)})
+	}
+
+	slices.SortStableFunc(pager.Cities, func(a, b PagerCity) int {
+		return cmp.Or(cmp.Compare(b.MMI, a.MMI), cmp.Compare(b.Population, a.Population))
+	})
+	pager.Cities = pager.Cities[:min(len(pager.Cities), PAGERMAXCITIES)]
+
+	return pager, nil
+}
+
+var romanMMI = []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X"}
+
+// RomanMMI returns the roman numeral of an MMI level.
+func RomanMMI(mmi int) string {
+	if mmi < 1 || mmi > len(romanMMI) {
+		return fmt.Sprint(mmi)
+	}
+	return romanMMI[mmi-1]
+}
+
+// StdoutPager outputs the PAGER section of the single event view.
+func StdoutPager(p *Pager) {
+	if p == nil {
+		fmt.Fprintf(os.Stdout, "\nNo PAGER estimate found for the event.\n")
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "\nPAGER Loss Estimate (source: %s)\n--------------------\n", p.Source)
+	if len(p.AlertLevel) != 0 {
+		fmt.Fprintf(os.Stdout, "Alert Level: %s\n", p.AlertLevel)
+	}
+	if p.MaxMMI != nil {
+		fmt.Fprintf(os.Stdout, "Maximum Estimated Intensity (MMI): %.1f\n", *p.MaxMMI)
+	}
+	for _, alert := range []struct {
+		name  string
+		alert LossAlert
+	}{{"Estimated Fatalities", p.Fatality}, {"Estimated Economic Losses", p.Economic}} {
+		fmt.Fprintf(os.Stdout, "%s Alert Level: %s\n", alert.name, alert.alert.Level)
+		for _, bin := range alert.alert.Bins {
+			fmt.Fprintf(os.Stdout, "  %s: %5.1f%%\n", lossRange(bin, alert.alert.Units), bin.Probability*100)
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "Population Exposure:\n")
+	for _, e := range p.Exposure {
+		if e.Population > 0 {
+			fmt.Fprintf(os.Stdout, "  MMI %-4s %12d\n", RomanMMI(e.MMI), e.Population)
+		}
+	}
+
+	if len(p.Cities) > 0 {
+		fmt.Fprintf(os.Stdout, "Most Exposed Cities:\n")
+		fmt.Fprintf(os.Stdout, "  %-5s %-30s %-7s %12s\n", "MMI", "City", "Country", "Population")
+		for _, c := range p.Cities {
+			fmt.Fprintf(os.Stdout, "  %-5.1f %-30s %-7s %12d\n", c.MMI, c.Name, c.Country, c.Population)
+		}
+	}
+}
+
+// lossRange formats the range of losses of a bin, e.g. "10-100 fatalities",
+// or "1,000+ fatalities" for the open-ended last bin.
+func lossRange(bin LossBin, units string) string {
+	units = strings.TrimSpace(units)
+	if bin.Max <= bin.Min {
+		return fmt.Sprintf("%s+ %s", groupDigits(bin.Min), units)
+	}
+	return fmt.Sprintf("%s-%s %s", groupDigits(bin.Min), groupDigits(bin.Max), units)
+}
+
+// groupDigits formats a whole number with thousands separators.
+func groupDigits(f float64) string {
+	digits := fmt.Sprintf("%.0f", f)
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
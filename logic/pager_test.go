@@ -0,0 +1,115 @@
+package logic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+const pagerAlertsJSON = `{
+  "fatality": {"level": "yellow", "units": "fatalities", "bins": [
+    {"min": 0, "max": 1, "probability": 0.35, "color": "green"},
+    {"min": 1, "max": 100, "probability": 0.6, "color": "yellow"},
+    {"min": 100, "max": 0, "probability": 0.05, "color": "red"}]},
+  "economic": {"level": "orange", "units": "USD", "bins": [
+    {"min": 0, "max": 1000000, "probability": 0.1, "color": "green"}]}
+}`
+
+const pagerExposuresJSON = `{
+  "population_exposure": {"mmi": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10],
+    "aggregated_exposure": [0, 0, 1200.4, 35000, 410000, 92000, 5100, 0, 0, 0]}
+}`
+
+const pagerCitiesJSON = `[
+  {"name": "Sendai", "ccode": "JP", "lat": 38.27, "lon": 140.87, "pop": 1063103, "mmi": 6.1},
+  {"name": "Ishinomaki", "ccode": "JP", "lat": 38.42, "lon": 141.3, "pop": 117233, "mmi": 6.8},
+  {"name": "Natori", "ccode": "JP", "lat": 38.17, "lon": 140.89, "pop": 73134, "mmi": 6.1},
+  {"name": "Tokyo", "ccode": "JP", "lat": 35.69, "lon": 139.69, "pop": 8336599, "mmi": 3.2}
+]`
+
+func TestExtractPager(t *testing.T) {
+	p, err := ExtractPager([]byte(pagerAlertsJSON), []byte(pagerExposuresJSON), []byte(pagerCitiesJSON))
+	if err != nil {
+		t.Fatalf("ExtractPager() = %v", err)
+	}
+
+	if p.Fatality.Level != "yellow" || p.Economic.Level != "orange" || len(p.Fatality.Bins) != 3 || p.Fatality.Bins[1].Probability != 0.6 {
+		t.Errorf("ExtractPager() alerts = %+v %+v", p.Fatality, p.Economic)
+	}
+	if len(p.Exposure) != 10 || p.Exposure[4] != (PagerExposure{5, 410000}) || p.Exposure[2].Population != 1200 {
+		t.Errorf("ExtractPager() exposure = %v", p.Exposure)
+	}
+
+	var cities []string
+	for _, c := range p.Cities {
+		cities = append(cities, c.Name)
+	}
+	if want := []string{"Ishinomaki", "Sendai", "Natori", "Tokyo"}; !slices.Equal(cities, want) {
+		t.Errorf("ExtractPager() cities = %v; want %v", cities, want)
+	}
+
+	if _, err := ExtractPager([]byte("{"), []byte(pagerExposuresJSON), []byte(pagerCitiesJSON)); err == nil {
+		t.Errorf("ExtractPager() with malformed alerts = nil; want an error")
+	}
+}
+
+func TestRequestPager(t *testing.T) {
+	files := map[string]string{
+		"/alerts.json":    pagerAlertsJSON,
+		"/exposures.json": pagerExposuresJSON,
+		"/cities.json":    pagerCitiesJSON,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(files[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	d := &Detail{Props: DetailProperties{Products: Products{PRODUCTLOSSPAGER: {{
+		Type:       PRODUCTLOSSPAGER,
+		Source:     "us",
+		Properties: map[string]string{"alertlevel": "yellow", "maxmmi": "8.1"},
+		Contents: map[string]Content{
+			PAGERALERTSPATH:    {URL: server.URL + "/alerts.json"},
+			PAGEREXPOSURESPATH: {URL: server.URL + "/exposures.json"},
+			PAGERCITIESPATH:    {URL: server.URL + "/cities.json"},
+		},
+	}}}}}
+
+	p, err := RequestPager(d)
+	if err != nil || p.Source != "us" || p.AlertLevel != "yellow" || p.MaxMMI == nil || *p.MaxMMI != 8.1 || p.Fatality.Level != "yellow" || len(p.Cities) != 4 {
+		t.Errorf("RequestPager() = %+v %v", p, err)
+	}
+
+	delete(d.Props.Products[PRODUCTLOSSPAGER][0].Contents, PAGERCITIESPATH)
+	if _, err := RequestPager(d); !errors.Is(err, ErrContentMissing) || errors.Is(err, ErrProductMissing) {
+		t.Errorf("RequestPager() without cities.json = %v; want %v", err, ErrContentMissing)
+	}
+
+	delete(d.Props.Products, PRODUCTLOSSPAGER)
+	if _, err := RequestPager(d); !errors.Is(err, ErrProductMissing) {
+		t.Errorf("RequestPager() without losspager = %v; want %v", err, ErrProductMissing)
+	}
+}
+
+type GroupDigitsTest struct {
+	in  float64
+	out string
+}
+
+func TestGroupDigits(t *testing.T) {
+	gTests := []GroupDigitsTest{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{1000000, "1,000,000"},
+		{123456.7, "123,457"},
+	}
+
+	for _, test := range gTests {
+		if got := groupDigits(test.in); got != test.out {
+			t.Errorf("groupDigits(%v) = %q; want %q", test.in, got, test.out)
+		}
+	}
+}
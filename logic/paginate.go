@@ -0,0 +1,137 @@
+package logic
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+)
+
+// PAGEORDER is the order records are requested in while paging. New events
+// are appended at the end in this order, so they cannot shift the offsets of
+// the pages still to be requested.
+const PAGEORDER = "time-asc"
+
+// requestContent is replaced in tests to serve the count and query methods.
+var requestContent = RequestContent
+
+// page is a window of records requested with the FDSN offset and limit
+// parameters. Offsets start at 1.
+type page struct {
+	offset int
+	limit  int
+}
+
+// RequestAllFeatures retrieves every record matching the flags as a single
+// response. Unless a limit keeps the records within one response, the count
+// method is asked first and, when more records match than the service allows
+// in one response, the records are requested in pages in PAGEORDER, merged,
+// and ordered as the flags ask. Progress is reported to progress for each
+// page.
+func RequestAllFeatures(flags FDSNFlags, progress io.Writer) (*USGSResponse, error) {
+	flags.Format = "json"
+	if flags.Limit > 0 && flags.Limit <= FDSNMAXLIMIT {
+		return requestResponse(flags)
+	}
+
+	count, err := RequestCount(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := splitPages(count.Count, count.MaxAllowed, flags.Offset, flags.Limit)
+	switch len(pages) {
+	case 0:
+		return &USGSResponse{Type: "FeatureCollection", Features: Features{}}, nil
+	case 1:
+		return requestResponse(flags)
+	}
+
+	// The service orders records by descending time unless told otherwise.
+	order := cmp.Or(flags.OrderBy, "time")
+	flags.OrderBy = PAGEORDER
+
+	var merged *USGSResponse
+	var features []Features
+	for i, p := range pages {
+		fmt.Fprintf(progress, "Fetching page %d/%d (records %d-%d of %d)\n",
+			i+1, len(pages), p.offset, p.offset+p.limit-1, count.Count)
+
+		flags.Offset = p.offset
+		flags.Limit = p.limit
+		res, err := requestResponse(flags)
+		if err != nil {
+			return nil, err
+		}
+
+		if merged == nil {
+			merged = res
+		}
+		features = append(features, res.Features)
+
+		if len(res.Features) < p.limit {
+			break
+		}
+	}
+
+	merged.Features = mergeFeatures(features...)
+	if err := SortFeatures(merged.Features, order); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func requestResponse(flags FDSNFlags) (*USGSResponse, error) {
+	endpoint, err := ExtractFDSNParams("query", flags)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := requestContent(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractResponse(content)
+}
+
+// splitPages divides the records matched by a count into pages of at most
+// maxAllowed records, honoring a user given offset and limit where set.
+func splitPages(count, maxAllowed, offset, limit int) []page {
+	if maxAllowed <= 0 {
+		maxAllowed = FDSNMAXLIMIT
+	}
+	if offset <= 0 {
+		offset = 1
+	}
+
+	remaining := count - offset + 1
+	if limit > 0 {
+		remaining = min(remaining, limit)
+	}
+
+	var pages []page
+	for remaining > 0 {
+		p := page{offset: offset, limit: min(remaining, maxAllowed)}
+		pages = append(pages, p)
+		offset += p.limit
+		remaining -= p.limit
+	}
+	return pages
+}
+
+// mergeFeatures joins pages of Features in order, keeping only the first
+// occurrence of each event id. Records may shift between pages when events
+// are added while the pages are requested.
+func mergeFeatures(pages ...Features) Features {
+	seen := make(map[string]bool)
+	merged := make(Features, 0)
+	for _, features := range pages {
+		for _, f := range features {
+			if seen[f.Id] {
+				continue
+			}
+			seen[f.Id] = true
+			merged = append(merged, f)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,114 @@
+package logic
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type PageTest struct {
+	count, maxAllowed, offset, limit int
+	out                              []page
+}
+
+func TestSplitPages(t *testing.T) {
+	pTests := []PageTest{
+		{0, 20000, 0, 0, nil},
+		{150, 20000, 0, 0, []page{{1, 150}}},
+		{45000, 20000, 0, 0, []page{{1, 20000}, {20001, 20000}, {40001, 5000}}},
+		{45000, 20000, 0, 30000, []page{{1, 20000}, {20001, 10000}}},
+		{45000, 20000, 10001, 0, []page{{10001, 20000}, {30001, 15000}}},
+		{45000, 0, 0, 0, []page{{1, 20000}, {20001, 20000}, {40001, 5000}}},
+		{100, 20000, 101, 0, nil},
+	}
+
+	for _, test := range pTests {
+		pages := splitPages(test.count, test.maxAllowed, test.offset, test.limit)
+		if !slices.Equal(pages, test.out) {
+			t.Errorf("splitPages(%d, %d, %d, %d) = %v; want %v", test.count, test.maxAllowed, test.offset, test.limit, pages, test.out)
+		}
+	}
+}
+
+func TestMergeFeatures(t *testing.T) {
+	first := Features{{Id: "a"}, {Id: "b"}, {Id: "c"}}
+	second := Features{{Id: "c"}, {Id: "d"}}
+	third := Features{{Id: "a"}, {Id: "e"}}
+
+	var ids []string
+	for _, f := range mergeFeatures(first, second, third) {
+		ids = append(ids, f.Id)
+	}
+
+	want := []string{"a", "b", "c", "d", "e"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("mergeFeatures() = %v; want %v", ids, want)
+	}
+}
+
+// fakeService serves the count and query methods over count records whose
+// times are their record numbers, ordered as the orderby parameter asks.
+func fakeService(t *testing.T, count, maxAllowed int, requests *[]url.Values) func(string) ([]byte, error) {
+	return func(endpoint string) ([]byte, error) {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			t.Fatalf("requestContent(%q) = %v", endpoint, err)
+		}
+		v := u.Query()
+		*requests = append(*requests, v)
+
+		if strings.HasSuffix(u.Path, "/count") {
+			return []byte(fmt.Sprintf(`{"count":%d,"maxAllowed":%d}`, count, maxAllowed)), nil
+		}
+
+		offset, _ := strconv.Atoi(v.Get("offset"))
+		limit, _ := strconv.Atoi(v.Get("limit"))
+		offset = max(offset, 1)
+		if limit == 0 {
+			limit = maxAllowed
+		}
+
+		var features []string
+		for i := offset; i < offset+limit && i <= count; i++ {
+			time := i
+			if v.Get("orderby") != "time-asc" {
+				time = count - i + 1
+			}
+			features = append(features, fmt.Sprintf(`{"id":"e%d","properties":{"time":%d}}`, time, time))
+		}
+		return []byte(`{"type":"FeatureCollection","features":[` + strings.Join(features, ",") + `]}`), nil
+	}
+}
+
+func TestRequestAllFeatures(t *testing.T) {
+	defer func(request func(string) ([]byte, error)) { requestContent = request }(requestContent)
+
+	var requests []url.Values
+	requestContent = fakeService(t, 25, 10, &requests)
+	res, err := RequestAllFeatures(FDSNFlags{}, io.Discard)
+	if err != nil {
+		t.Fatalf("RequestAllFeatures() = %v", err)
+	}
+	if len(requests) != 4 || requests[1].Get("orderby") != PAGEORDER || requests[3].Get("orderby") != PAGEORDER {
+		t.Errorf("RequestAllFeatures() requests = %v; want a count and 3 pages in %s", requests, PAGEORDER)
+	}
+	if len(res.Features) != 25 || res.Features[0].Id != "e25" || res.Features[24].Id != "e1" {
+		t.Errorf("RequestAllFeatures() = %d records from %s; want 25 from e25", len(res.Features), featureIds(res.Features)[0])
+	}
+
+	requests = nil
+	res, err = RequestAllFeatures(FDSNFlags{OrderBy: "time-asc"}, io.Discard)
+	if err != nil || len(res.Features) != 25 || res.Features[0].Id != "e1" {
+		t.Errorf("RequestAllFeatures(time-asc) = %v %v", featureIds(res.Features), err)
+	}
+
+	requests = nil
+	res, err = RequestAllFeatures(FDSNFlags{Limit: 5}, io.Discard)
+	if err != nil || len(requests) != 1 || len(res.Features) != 5 || requests[0].Has("orderby") {
+		t.Errorf("RequestAllFeatures(limit 5) = %d records in %d requests %v; want 5 in a single request", len(res.Features), len(requests), err)
+	}
+}
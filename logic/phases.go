@@ -0,0 +1,226 @@
+package logic
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrPhaseDataOrigin = errors.New("phase data has no origin")
+
+// PHASEDATAPATH is the content path of the QuakeML document of the phase-data
+// product.
+const PHASEDATAPATH = "quakeml.xml"
+
+// Phase is an arrival of the preferred origin together with the pick it was
+// associated with. Distance and Azimuth are from the epicenter to the station
+// in degrees, TravelTime and Residual are in seconds.
+type Phase struct {
+	Network        string    `json:"network"`
+	Station        string    `json:"station"`
+	Location       string    `json:"location,omitempty"`
+	Channel        string    `json:"channel"`
+	Phase          string    `json:"phase"`
+	Time           time.Time `json:"time"`
+	TravelTime     float64   `json:"travelTime"`
+	Distance       float64   `json:"distance"`
+	Azimuth        float64   `json:"azimuth"`
+	Residual       float64   `json:"residual"`
+	Weight         float64   `json:"weight"`
+	Onset          string    `json:"onset,omitempty"`
+	Polarity       string    `json:"polarity,omitempty"`
+	EvaluationMode string    `json:"evaluationMode,omitempty"`
+}
+
+// quakeML holds the parts of a QuakeML 1.2 document read for phases. Elements
+// are matched by their local names.
+type quakeML struct {
+	Events []qmlEvent `xml:"eventParameters>event"`
+}
+
+type qmlEvent struct {
+	PreferredOriginID string      `xml:"preferredOriginID"`
+	Picks             []qmlPick   `xml:"pick"`
+	Origins           []qmlOrigin `xml:"origin"`
+}
+
+type qmlPick struct {
+	PublicID   string `xml:"publicID,attr"`
+	Time       string `xml:"time>value"`
+	WaveformID struct {
+		Network  string `xml:"networkCode,attr"`
+		Station  string `xml:"stationCode,attr"`
+		Location string `xml:"locationCode,attr"`
+		Channel  string `xml:"channelCode,attr"`
+	} `xml:"waveformID"`
+	Onset          string `xml:"onset"`
+	Polarity       string `xml:"polarity"`
+	EvaluationMode string `xml:"evaluationMode"`
+	PhaseHint      string `xml:"phaseHint"`
+}
+
+type qmlOrigin struct {
+	PublicID string       `xml:"publicID,attr"`
+	Time     string       `xml:"time>value"`
+	Arrivals []qmlArrival `xml:"arrival"`
+}
+
+type qmlArrival struct {
+	PickID       string   `xml:"pickID"`
+	Phase        string   `xml:"phase"`
+	Azimuth      float64  `xml:"azimuth"`
+	Distance     float64  `xml:"distance"`
+	TimeResidual float64  `xml:"timeResidual"`
+	TimeWeight   *float64 `xml:"timeWeight"`
+}
+
+// RequestPhases retrieves the phases of the event's preferred phase-data
+// product.
+func RequestPhases(d *Detail) ([]Phase, error) {
+	p, err := d.Props.Products.Preferred(PRODUCTPHASEDATA)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := p.RequestFile(PHASEDATAPATH)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractPhases(content)
+}
+
+// ExtractPhases reads the arrivals of the preferred origin of the first event
+// of a QuakeML document, ordered by distance and then by time.
+func ExtractPhases(content []byte) ([]Phase, error) {
+	var doc quakeML
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Events) == 0 || len(doc.Events[0].Origins) == 0 {
+		return nil, ErrPhaseDataOrigin
+	}
+	event := doc.Events[0]
+
+	origin := event.Origins[0]
+	for _, o := range event.Origins {
+		if o.PublicID == event.PreferredOriginID {
+			origin = o
+		}
+	}
+	originTime, err := parseQuakeMLTime(origin.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	picks := make(map[string]qmlPick, len(event.Picks))
+	for _, pick := range event.Picks {
+		picks[pick.PublicID] = pick
+	}
+
+	phases := make([]Phase, 0, len(origin.Arrivals))
+	for _, arrival := range origin.Arrivals {
+		pick, ok := picks[strings.TrimSpace(arrival.PickID)]
+		if !ok {
+			continue
+		}
+		pickTime, err := parseQuakeMLTime(pick.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		phase := Phase{
+			Network:        pick.WaveformID.Network,
+			Station:        pick.WaveformID.Station,
+			Location:       strings.Trim(pick.WaveformID.Location, "-"),
+			Channel:        pick.WaveformID.Channel,
+			Phase:          cmp.Or(arrival.Phase, pick.PhaseHint),
+			Time:           pickTime,
+			TravelTime:     pickTime.Sub(originTime).Seconds(),
+			Distance:       arrival.Distance,
+			Azimuth:        arrival.Azimuth,
+			Residual:       arrival.TimeResidual,
+			Weight:         1,
+			Onset:          pick.Onset,
+			Polarity:       pick.Polarity,
+			EvaluationMode: pick.EvaluationMode,
+		}
+		if arrival.TimeWeight != nil {
+			phase.Weight = *arrival.TimeWeight
+		}
+		phases = append(phases, phase)
+	}
+
+	slices.SortStableFunc(phases, func(a, b Phase) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), a.Time.Compare(b.Time))
+	})
+	return phases, nil
+}
+
+// parseQuakeMLTime parses a QuakeML time, which is UTC when it carries no
+// zone.
+func parseQuakeMLTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		t, err = time.Parse("2006-01-02T15:04:05.999999999", value)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("QuakeML time %q invalid", value)
+	}
+	return t.UTC(), nil
+}
+
+// PhaseColumns lists the columns written by WritePhasesCSV.
+var PhaseColumns = []string{
+	"network", "station", "location", "channel", "phase", "time", "travelTime",
+	"distance", "azimuth", "residual", "weight", "onset", "polarity", "evaluationMode",
+}
+
+// WritePhasesCSV serializes phases as CSV records preceded by a header.
+func WritePhasesCSV(w io.Writer, phases []Phase) error {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Write(PhaseColumns)
+	for _, p := range phases {
+		csvWriter.Write([]string{
+			p.Network,
+			p.Station,
+			p.Location,
+			p.Channel,
+			p.Phase,
+			p.Time.Format(CSVTIMEFORMAT),
+			formatFloat(p.TravelTime),
+			formatFloat(p.Distance),
+			formatFloat(p.Azimuth),
+			formatFloat(p.Residual),
+			formatFloat(p.Weight),
+			p.Onset,
+			p.Polarity,
+			p.EvaluationMode,
+		})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// StdoutPhases outputs a table of the phases of an event.
+func StdoutPhases(phases []Phase) {
+	if len(phases) == 0 {
+		fmt.Fprintf(os.Stdout, "No phase arrivals found for the event.\n")
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "%-18s %-6s %-12s %9s %9s %8s %9s %6s\n",
+		"Station", "Phase", "Time (UTC)", "Travel(s)", "Dist(deg)", "Azimuth", "Resid(s)", "Weight")
+	for _, p := range phases {
+		station := strings.Join([]string{p.Network, p.Station, p.Location, p.Channel}, ".")
+		fmt.Fprintf(os.Stdout, "%-18s %-6s %-12s %9.2f %9.3f %8.1f %9.2f %6.2f\n",
+			station, p.Phase, p.Time.Format("15:04:05.00"), p.TravelTime, p.Distance, p.Azimuth, p.Residual, p.Weight)
+	}
+}
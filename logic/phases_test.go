@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const phaseDataXML = `<?xml version="1.0" encoding="UTF-8"?>
+<q:quakeml xmlns="http://quakeml.org/xmlns/bed/1.2" xmlns:q="http://quakeml.org/xmlns/quakeml/1.2">
+  <eventParameters publicID="quakeml:us.anss.org/eventparameters/7000abcd">
+    <event publicID="quakeml:us.anss.org/event/7000abcd">
+      <preferredOriginID>quakeml:us.anss.org/origin/7000abcd</preferredOriginID>
+      <pick publicID="quakeml:us.anss.org/pick/1">
+        <time><value>2024-06-15T12:00:41.50Z</value></time>
+        <waveformID networkCode="IU" stationCode="MAJO" locationCode="00" channelCode="BHZ"/>
+        <onset>impulsive</onset>
+        <polarity>positive</polarity>
+        <evaluationMode>manual</evaluationMode>
+        <phaseHint>P</phaseHint>
+      </pick>
+      <pick publicID="quakeml:us.anss.org/pick/2">
+        <time><value>2024-06-15T12:00:20.25</value></time>
+        <waveformID networkCode="JP" stationCode="JSD" locationCode="--" channelCode="BHZ"/>
+        <evaluationMode>automatic</evaluationMode>
+        <phaseHint>P</phaseHint>
+      </pick>
+      <pick publicID="quakeml:us.anss.org/pick/3">
+        <time><value>2024-06-15T12:01:10.00Z</value></time>
+        <waveformID networkCode="IU" stationCode="MAJO" locationCode="00" channelCode="BHN"/>
+        <phaseHint>S</phaseHint>
+      </pick>
+      <origin publicID="quakeml:us.anss.org/origin/other">
+        <time><value>2024-06-15T11:00:00Z</value></time>
+      </origin>
+      <origin publicID="quakeml:us.anss.org/origin/7000abcd">
+        <time><value>2024-06-15T12:00:00.00Z</value></time>
+        <arrival publicID="quakeml:us.anss.org/arrival/1">
+          <pickID>quakeml:us.anss.org/pick/1</pickID>
+          <phase>P</phase>
+          <azimuth>241.3</azimuth>
+          <distance>5.12</distance>
+          <timeResidual>-0.4</timeResidual>
+          <timeWeight>0.8</timeWeight>
+        </arrival>
+        <arrival publicID="quakeml:us.anss.org/arrival/3">
+          <pickID>quakeml:us.anss.org/pick/3</pickID>
+          <phase>S</phase>
+          <azimuth>241.3</azimuth>
+          <distance>5.12</distance>
+          <timeResidual>1.1</timeResidual>
+        </arrival>
+        <arrival publicID="quakeml:us.anss.org/arrival/2">
+          <pickID>quakeml:us.anss.org/pick/2</pickID>
+          <azimuth>12</azimuth>
+          <distance>1.5</distance>
+          <timeResidual>0.2</timeResidual>
+        </arrival>
+        <arrival publicID="quakeml:us.anss.org/arrival/9">
+          <pickID>quakeml:us.anss.org/pick/9</pickID>
+          <phase>P</phase>
+        </arrival>
+      </origin>
+    </event>
+  </eventParameters>
+</q:quakeml>`
+
+func TestExtractPhases(t *testing.T) {
+	phases, err := ExtractPhases([]byte(phaseDataXML))
+	if err != nil {
+		t.Fatalf("ExtractPhases() = %v", err)
+	}
+	if len(phases) != 3 {
+		t.Fatalf("ExtractPhases() = %d phases; want 3", len(phases))
+	}
+
+	near := phases[0]
+	if near.Station != "JSD" || near.Location != "" || near.Phase != "P" || near.TravelTime != 20.25 ||
+		near.Weight != 1 || near.Time != time.Date(2024, 6, 15, 12, 0, 20, 250000000, time.UTC) {
+		t.Errorf("ExtractPhases()[0] = %+v", near)
+	}
+
+	p := phases[1]
+	want := Phase{"IU", "MAJO", "00", "BHZ", "P", time.Date(2024, 6, 15, 12, 0, 41, 500000000, time.UTC),
+		41.5, 5.12, 241.3, -0.4, 0.8, "impulsive", "positive", "manual"}
+	if p != want {
+		t.Errorf("ExtractPhases()[1] = %+v; want %+v", p, want)
+	}
+	if phases[2].Phase != "S" || phases[2].Channel != "BHN" {
+		t.Errorf("ExtractPhases()[2] = %+v", phases[2])
+	}
+
+	if _, err := ExtractPhases([]byte(`<quakeml><eventParameters/></quakeml>`)); !errors.Is(err, ErrPhaseDataOrigin) {
+		t.Errorf("ExtractPhases() without an origin = %v; want %v", err, ErrPhaseDataOrigin)
+	}
+}
+
+func TestWritePhasesCSV(t *testing.T) {
+	phases, _ := ExtractPhases([]byte(phaseDataXML))
+
+	var b bytes.Buffer
+	if err := WritePhasesCSV(&b, phases[1:2]); err != nil {
+		t.Fatalf("WritePhasesCSV() = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	want := "IU,MAJO,00,BHZ,P,2024-06-15T12:00:41.500Z,41.5,5.12,241.3,-0.4,0.8,impulsive,positive,manual"
+	if len(lines) != 2 || lines[0] != strings.Join(PhaseColumns, ",") || lines[1] != want {
+		t.Errorf("WritePhasesCSV() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
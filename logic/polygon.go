@@ -0,0 +1,296 @@
+package logic
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrFlagPolygonOption = errors.New("--polygon option invalid")
+
+// Ring is a closed sequence of [longitude, latitude] vertices.
+type Ring [][2]float64
+
+// Polygon is one or more polygons read from a GeoJSON or WKT file. The first
+// Ring of each part is its outer boundary and any following rings are holes.
+type Polygon struct {
+	Parts [][]Ring
+}
+
+// polygonPart is an outer boundary followed by its holes.
+type polygonPart []Ring
+
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Features    []geoJSONObject `json:"features"`
+}
+
+// ParsePolygonFile reads a polygon from path. The file holds either a GeoJSON
+// Polygon, MultiPolygon, Feature or FeatureCollection, or a WKT POLYGON or
+// MULTIPOLYGON. An empty path returns a nil Polygon.
+func ParsePolygonFile(path string) (*Polygon, error) {
+	if len(strings.TrimSpace(path)) == 0 {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parsePolygon(string(content))
+}
+
+func parsePolygon(content string) (*Polygon, error) {
+	content = strings.TrimSpace(content)
+
+	p := new(Polygon)
+	if strings.HasPrefix(content, "{") {
+		var obj geoJSONObject
+		if err := json.Unmarshal([]byte(content), &obj); err != nil {
+			return nil, ErrFlagPolygonOption
+		}
+		if err := p.addGeoJSON(&obj); err != nil {
+			return nil, err
+		}
+	} else if err := p.addWKT(content); err != nil {
+		return nil, err
+	}
+
+	if len(p.Parts) == 0 {
+		return nil, ErrFlagPolygonOption
+	}
+	for _, part := range p.Parts {
+		if len(part) == 0 {
+			return nil, ErrFlagPolygonOption
+		}
+		for _, ring := range part {
+			if len(ring) < 3 {
+				return nil, ErrFlagPolygonOption
+			}
+			ring.unwrap()
+		}
+	}
+	return p, nil
+}
+
+// unwrap makes the longitudes of a ring continuous: an edge jumping more than
+// 180 degrees is taken to cross the antimeridian, so the following vertices
+// are shifted by 360 degrees and may lie beyond [-180, 180].
+func (r Ring) unwrap() {
+	for i := 1; i < len(r); i++ {
+		for r[i][0]-r[i-1][0] > 180 {
+			r[i][0] -= 360
+		}
+		for r[i][0]-r[i-1][0] < -180 {
+			r[i][0] += 360
+		}
+	}
+}
+
+func (p *Polygon) addGeoJSON(obj *geoJSONObject) error {
+	switch obj.Type {
+	case "FeatureCollection":
+		for i := range obj.Features {
+			if err := p.addGeoJSON(&obj.Features[i]); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if obj.Geometry == nil {
+			return ErrFlagPolygonOption
+		}
+		return p.addGeoJSON(obj.Geometry)
+	case "Polygon":
+		var part []Ring
+		if err := json.Unmarshal(obj.Coordinates, &part); err != nil {
+			return ErrFlagPolygonOption
+		}
+		p.Parts = append(p.Parts, part)
+	case "MultiPolygon":
+		var parts [][]Ring
+		if err := json.Unmarshal(obj.Coordinates, &parts); err != nil {
+			return ErrFlagPolygonOption
+		}
+		p.Parts = append(p.Parts, parts...)
+	default:
+		return ErrFlagPolygonOption
+	}
+	return nil
+}
+
+// addWKT scans a POLYGON or MULTIPOLYGON text by parenthesis depth: a new
+// part opens one level above the rings, and each ring is the coordinate list
+// found at the deepest level.
+func (p *Polygon) addWKT(content string) error {
+	upper := strings.ToUpper(content)
+
+	var ringDepth int
+	switch {
+	case strings.HasPrefix(upper, "MULTIPOLYGON"):
+		content = content[len("MULTIPOLYGON"):]
+		ringDepth = 3
+	case strings.HasPrefix(upper, "POLYGON"):
+		content = content[len("POLYGON"):]
+		ringDepth = 2
+	default:
+		return ErrFlagPolygonOption
+	}
+
+	depth, start := 0, 0
+	for i, r := range content {
+		switch r {
+		case '(':
+			depth++
+			if depth == ringDepth-1 {
+				p.Parts = append(p.Parts, nil)
+			}
+			if depth == ringDepth {
+				start = i + 1
+			}
+		case ')':
+			if depth == ringDepth {
+				ring, err := parseWKTRing(content[start:i])
+				if err != nil {
+					return err
+				}
+				last := len(p.Parts) - 1
+				p.Parts[last] = append(p.Parts[last], ring)
+			}
+			depth--
+			if depth < 0 {
+				return ErrFlagPolygonOption
+			}
+		default:
+			if depth == 0 && !strings.ContainsRune(" \t\r\n", r) {
+				return ErrFlagPolygonOption
+			}
+		}
+	}
+
+	if depth != 0 {
+		return ErrFlagPolygonOption
+	}
+	return nil
+}
+
+func parseWKTRing(coords string) (Ring, error) {
+	var ring Ring
+	for _, pair := range strings.Split(coords, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) < 2 {
+			return nil, ErrFlagPolygonOption
+		}
+		lon, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, ErrFlagPolygonOption
+		}
+		lat, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, ErrFlagPolygonOption
+		}
+		ring = append(ring, [2]float64{lon, lat})
+	}
+	return ring, nil
+}
+
+// Contains reports whether the point lies inside any part of the polygon and
+// outside of that part's holes. As rings may extend past the antimeridian the
+// point is also tried one turn east and west.
+func (p *Polygon) Contains(lat, lon float64) bool {
+	for _, part := range p.Parts {
+		for _, turn := range []float64{0, 360, -360} {
+			if polygonPart(part).contains(lat, lon+turn) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (part polygonPart) contains(lat, lon float64) bool {
+	if !part[0].contains(lat, lon) {
+		return false
+	}
+	for _, hole := range part[1:] {
+		if hole.contains(lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains casts a ray from the point towards increasing longitude and counts
+// the ring edges it crosses.
+func (r Ring) contains(lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		lonI, latI := r[i][0], r[i][1]
+		lonJ, latJ := r[j][0], r[j][1]
+		if (latI > lat) != (latJ > lat) &&
+			lon < (lonJ-lonI)*(lat-latI)/(latJ-latI)+lonI {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Bounds returns the smallest BBox enclosing the outer boundary of every part.
+// Parts on either side of the antimeridian, or extending past it, give a box
+// that crosses it (MinLon > MaxLon), as accepted by ParseBBox.
+func (p *Polygon) Bounds() *BBox {
+	b := &BBox{MinLat: 90, MaxLat: -90}
+
+	// Longitude intervals of the parts within [-180, 180], split where they
+	// cross the antimeridian.
+	var spans [][2]float64
+	for _, part := range p.Parts {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, vertex := range part[0] {
+			lo, hi = min(lo, vertex[0]), max(hi, vertex[0])
+			b.MinLat = min(b.MinLat, vertex[1])
+			b.MaxLat = max(b.MaxLat, vertex[1])
+		}
+
+		if hi-lo >= 360 {
+			spans = append(spans, [2]float64{-180, 180})
+			continue
+		}
+		turns := math.Floor((lo + 180) / 360)
+		lo, hi = lo-turns*360, hi-turns*360
+		if hi > 180 {
+			spans = append(spans, [2]float64{lo, 180}, [2]float64{-180, hi - 360})
+		} else {
+			spans = append(spans, [2]float64{lo, hi})
+		}
+	}
+
+	slices.SortFunc(spans, func(a, b [2]float64) int { return cmp.Compare(a[0], b[0]) })
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span[0] <= last[1] {
+			last[1] = max(last[1], span[1])
+		} else {
+			merged = append(merged, span)
+		}
+	}
+
+	// The box leaves out the widest longitude gap between the parts, which is
+	// the one around the antimeridian unless a part reaches across it.
+	b.MinLon, b.MaxLon = merged[0][0], merged[len(merged)-1][1]
+	widest := merged[0][0] + 360 - merged[len(merged)-1][1]
+	for i := 1; i < len(merged); i++ {
+		if gap := merged[i][0] - merged[i-1][1]; gap > widest {
+			widest = gap
+			b.MinLon, b.MaxLon = merged[i][0], merged[i-1][1]
+		}
+	}
+	return b
+}
@@ -0,0 +1,120 @@
+package logic
+
+import "testing"
+
+type PolygonTest struct {
+	in    string
+	parts int
+	err   error
+}
+
+type ContainsTest struct {
+	lat, lon float64
+	out      bool
+}
+
+const donutWKT = `POLYGON ((-10 -10, 10 -10, 10 10, -10 10, -10 -10), (-5 -5, 5 -5, 5 5, -5 5, -5 -5))`
+
+func TestParsePolygon(t *testing.T) {
+	pTests := []PolygonTest{
+		{donutWKT, 1, nil},
+		{"polygon((0 0, 1 0, 1 1, 0 0))", 1, nil},
+		{"MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5), (5.1 5.1, 5.2 5.1, 5.2 5.2, 5.1 5.1)))", 2, nil},
+		{`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}`, 1, nil},
+		{`{"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]], [[[5, 5], [6, 5], [6, 6], [5, 5]]]]}`, 2, nil},
+		{`{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}}`, 1, nil},
+		{`{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}}]}`, 1, nil},
+		{`{"type": "Point", "coordinates": [0, 0]}`, 0, ErrFlagPolygonOption},
+		{`{"type": "FeatureCollection", "features": []}`, 0, ErrFlagPolygonOption},
+		{"POLYGON ((0 0, 1 0))", 0, ErrFlagPolygonOption},
+		{"POLYGON ((0 0, 1 0, 1 1, 0 0)", 0, ErrFlagPolygonOption},
+		{"POLYGON ((0 0, 1 a, 1 1, 0 0))", 0, ErrFlagPolygonOption},
+		{"LINESTRING (0 0, 1 1)", 0, ErrFlagPolygonOption},
+	}
+
+	for _, test := range pTests {
+		p, err := parsePolygon(test.in)
+		parts := 0
+		if p != nil {
+			parts = len(p.Parts)
+		}
+		if parts != test.parts || err != test.err {
+			t.Errorf("parsePolygon(%q) = %d parts %v; want %d parts %v", test.in, parts, err, test.parts, test.err)
+		}
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	p, err := parsePolygon(donutWKT)
+	if err != nil {
+		t.Fatalf("parsePolygon(%q) = %v", donutWKT, err)
+	}
+
+	cTests := []ContainsTest{
+		{7, 7, true},
+		{-7, 0, true},
+		{0, 0, false},
+		{11, 0, false},
+		{0, -11, false},
+	}
+
+	for _, test := range cTests {
+		if in := p.Contains(test.lat, test.lon); in != test.out {
+			t.Errorf("Contains(%v, %v) = %v; want %v", test.lat, test.lon, in, test.out)
+		}
+	}
+
+	b := p.Bounds()
+	if *b != (BBox{-10, 10, -10, 10}) {
+		t.Errorf("Bounds() = %v; want %v", *b, BBox{-10, 10, -10, 10})
+	}
+}
+
+type BoundsTest struct {
+	in  string
+	out BBox
+}
+
+func TestPolygonBounds(t *testing.T) {
+	bTests := []BoundsTest{
+		{donutWKT, BBox{-10, 10, -10, 10}},
+		{"POLYGON ((170 -20, -170 -20, -170 -10, 170 -10, 170 -20))", BBox{-20, -10, 170, -170}},
+		{"POLYGON ((170 -20, 190 -20, 190 -10, 170 -10, 170 -20))", BBox{-20, -10, 170, -170}},
+		{"MULTIPOLYGON (((170 0, 180 0, 180 5, 170 0)), ((-180 0, -175 0, -175 5, -180 0)))", BBox{0, 5, 170, -175}},
+		{"MULTIPOLYGON (((-30 0, -20 0, -20 5, -30 0)), ((20 0, 30 0, 30 5, 20 0)))", BBox{0, 5, -30, 30}},
+		{"MULTIPOLYGON (((-120 0, -110 0, -110 5, -120 0)), ((100 0, 110 0, 110 5, 100 0)))", BBox{0, 5, 100, -110}},
+	}
+
+	for _, test := range bTests {
+		p, err := parsePolygon(test.in)
+		if err != nil {
+			t.Fatalf("parsePolygon(%q) = %v", test.in, err)
+		}
+		if b := p.Bounds(); *b != test.out {
+			t.Errorf("Bounds() of %q = %v; want %v", test.in, *b, test.out)
+		}
+	}
+}
+
+func TestPolygonContainsAntimeridian(t *testing.T) {
+	in := "POLYGON ((170 -20, -170 -20, -170 -10, 170 -10, 170 -20))"
+	p, err := parsePolygon(in)
+	if err != nil {
+		t.Fatalf("parsePolygon(%q) = %v", in, err)
+	}
+
+	cTests := []ContainsTest{
+		{-15, 175, true},
+		{-15, -175, true},
+		{-15, 180, true},
+		{-15, 0, false},
+		{-15, 165, false},
+		{-15, -165, false},
+	}
+
+	for _, test := range cTests {
+		if in := p.Contains(test.lat, test.lon); in != test.out {
+			t.Errorf("Contains(%v, %v) = %v; want %v", test.lat, test.lon, in, test.out)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package logic
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OriginProperties are the properties of an origin or phase-data product.
+// Values the product does not provide are nil or empty.
+type OriginProperties struct {
+	Latitude             *float64
+	Longitude            *float64
+	Depth                *float64
+	EventTime            string
+	Magnitude            *float64
+	MagnitudeType        string
+	MagnitudeError       *float64
+	MagnitudeNumStations *int
+	NumStationsUsed      *int
+	NumPhasesUsed        *int
+	AzimuthalGap         *float64
+	MinimumDistance      *float64
+	StandardError        *float64
+	HorizontalError      *float64
+	VerticalError        *float64
+	ReviewStatus         string
+}
+
+// MechanismProperties are the properties of a moment-tensor or
+// focal-mechanism product. Nodal planes, axes and the tensor are only set when
+// every one of their components is provided.
+type MechanismProperties struct {
+	ScalarMoment         *float64
+	DerivedMagnitude     *float64
+	DerivedMagnitudeType string
+	DerivedDepth         *float64
+	PercentDoubleCouple  *float64
+	NodalPlanes          []NodalPlane
+	T                    *Axis
+	N                    *Axis
+	P                    *Axis
+	Tensor               *Tensor
+	BeachballSource      string
+}
+
+// ShakeMapProperties are the properties of a shakemap product, with peak
+// ground acceleration and spectral accelerations in %g and peak ground
+// velocity in cm/s.
+type ShakeMapProperties struct {
+	MaxMMI    *float64
+	MaxPGA    *float64
+	MaxPGV    *float64
+	MaxPSA03  *float64
+	MaxPSA10  *float64
+	MaxPSA30  *float64
+	MapStatus string
+	Version   string
+}
+
+// DYFIProperties are the properties of a dyfi product.
+type DYFIProperties struct {
+	MaxMMI       *float64
+	NumResponses *int
+}
+
+// PagerProperties are the properties of a losspager product.
+type PagerProperties struct {
+	AlertLevel string
+	MaxMMI     *float64
+}
+
+// FiniteFaultProperties are the properties of a finite-fault product.
+type FiniteFaultProperties struct {
+	Latitude             *float64
+	Longitude            *float64
+	Depth                *float64
+	ScalarMoment         *float64
+	DerivedMagnitude     *float64
+	DerivedMagnitudeType string
+}
+
+// OriginProperties reads the properties of an origin or phase-data product.
+func (p *Product) OriginProperties() OriginProperties {
+	props := p.Properties
+	return OriginProperties{
+		Latitude:             floatProperty(props, "latitude"),
+		Longitude:            floatProperty(props, "longitude"),
+		Depth:                floatProperty(props, "depth"),
+		EventTime:            props["eventtime"],
+		Magnitude:            floatProperty(props, "magnitude"),
+		MagnitudeType:        props["magnitude-type"],
+		MagnitudeError:       floatProperty(props, "magnitude-error"),
+		MagnitudeNumStations: intProperty(props, "magnitude-num-stations-used"),
+		NumStationsUsed:      intProperty(props, "num-stations-used"),
+		NumPhasesUsed:        intProperty(props, "num-phases-used"),
+		AzimuthalGap:         floatProperty(props, "azimuthal-gap"),
+		MinimumDistance:      floatProperty(props, "minimum-distance"),
+		StandardError:        floatProperty(props, "standard-error"),
+		HorizontalError:      floatProperty(props, "horizontal-error"),
+		VerticalError:        floatProperty(props, "vertical-error"),
+		ReviewStatus:         props["review-status"],
+	}
+}
+
+// MechanismProperties reads the properties of a moment-tensor or
+// focal-mechanism product. A nodal plane without a rake takes its slip.
+func (p *Product) MechanismProperties() MechanismProperties {
+	props := p.Properties
+	m := MechanismProperties{
+		ScalarMoment:         floatProperty(props, "scalar-moment"),
+		DerivedMagnitude:     floatProperty(props, "derived-magnitude"),
+		DerivedMagnitudeType: props["derived-magnitude-type"],
+		DerivedDepth:         floatProperty(props, "derived-depth"),
+		PercentDoubleCouple:  floatProperty(props, "percent-double-couple"),
+		T:                    axisProperty(props, "t-axis-"),
+		N:                    axisProperty(props, "n-axis-"),
+		P:                    axisProperty(props, "p-axis-"),
+		BeachballSource:      props["beachball-source"],
+	}
+
+	for i := 1; i <= 2; i++ {
+		prefix := fmt.Sprintf("nodal-plane-%d-", i)
+		rake := floatProperty(props, prefix+"rake")
+		if rake == nil {
+			rake = floatProperty(props, prefix+"slip")
+		}
+		strike, dip := floatProperty(props, prefix+"strike"), floatProperty(props, prefix+"dip")
+		if strike != nil && dip != nil && rake != nil {
+			m.NodalPlanes = append(m.NodalPlanes, NodalPlane{*strike, *dip, *rake})
+		}
+	}
+
+	var t Tensor
+	components := []struct {
+		key   string
+		value *float64
+	}{
+		{"tensor-mrr", &t.Mrr}, {"tensor-mtt", &t.Mtt}, {"tensor-mpp", &t.Mpp},
+		{"tensor-mrt", &t.Mrt}, {"tensor-mrp", &t.Mrp}, {"tensor-mtp", &t.Mtp},
+	}
+	complete := true
+	for _, c := range components {
+		if v := floatProperty(props, c.key); v != nil {
+			*c.value = *v
+		} else {
+			complete = false
+		}
+	}
+	if complete {
+		m.Tensor = &t
+	}
+
+	return m
+}
+
+// ShakeMapProperties reads the properties of a shakemap product.
+func (p *Product) ShakeMapProperties() ShakeMapProperties {
+	props := p.Properties
+	return ShakeMapProperties{
+		MaxMMI:    floatProperty(props, "maxmmi"),
+		MaxPGA:    floatProperty(props, "maxpga"),
+		MaxPGV:    floatProperty(props, "maxpgv"),
+		MaxPSA03:  floatProperty(props, "maxpsa03"),
+		MaxPSA10:  floatProperty(props, "maxpsa10"),
+		MaxPSA30:  floatProperty(props, "maxpsa30"),
+		MapStatus: props["map-status"],
+		Version:   props["version"],
+	}
+}
+
+// DYFIProperties reads the properties of a dyfi product.
+func (p *Product) DYFIProperties() DYFIProperties {
+	return DYFIProperties{
+		MaxMMI:       floatProperty(p.Properties, "maxmmi"),
+		NumResponses: intProperty(p.Properties, "num-responses"),
+	}
+}
+
+// PagerProperties reads the properties of a losspager product.
+func (p *Product) PagerProperties() PagerProperties {
+	return PagerProperties{
+		AlertLevel: p.Properties["alertlevel"],
+		MaxMMI:     floatProperty(p.Properties, "maxmmi"),
+	}
+}
+
+// FiniteFaultProperties reads the properties of a finite-fault product.
+func (p *Product) FiniteFaultProperties() FiniteFaultProperties {
+	props := p.Properties
+	return FiniteFaultProperties{
+		Latitude:             floatProperty(props, "latitude"),
+		Longitude:            floatProperty(props, "longitude"),
+		Depth:                floatProperty(props, "depth"),
+		ScalarMoment:         floatProperty(props, "scalar-moment"),
+		DerivedMagnitude:     floatProperty(props, "derived-magnitude"),
+		DerivedMagnitudeType: props["derived-magnitude-type"],
+	}
+}
+
+// floatProperty parses a product property as a number, or returns nil when
+// the property is absent or not a number.
+func floatProperty(props map[string]string, key string) *float64 {
+	value, ok := props[key]
+	if !ok {
+		return nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// intProperty parses a product property as a whole number, or returns nil
+// when the property is absent or not a whole number.
+func intProperty(props map[string]string, key string) *int {
+	value, ok := props[key]
+	if !ok {
+		return nil
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return &i
+}
+
+// axisProperty reads a principal axis from the properties starting with
+// prefix, or returns nil when its azimuth or plunge is missing.
+func axisProperty(props map[string]string, prefix string) *Axis {
+	azimuth := floatProperty(props, prefix+"azimuth")
+	plunge := floatProperty(props, prefix+"plunge")
+	if azimuth == nil || plunge == nil {
+		return nil
+	}
+	a := &Axis{Azimuth: *azimuth, Plunge: *plunge}
+	if value := floatProperty(props, prefix+"length"); value != nil {
+		a.Value = *value
+	}
+	return a
+}
+
+// ProductSummary describes the typed properties of a product in a line, such
+// as the location and magnitude of an origin or the peak shaking of a
+// ShakeMap. It is empty for product types without typed properties or when
+// none of them are provided.
+func ProductSummary(p *Product) string {
+	var parts []string
+	add := func(format string, args ...any) {
+		parts = append(parts, fmt.Sprintf(format, args...))
+	}
+
+	switch p.Type {
+	case PRODUCTORIGIN, PRODUCTPHASEDATA:
+		o := p.OriginProperties()
+		if o.Magnitude != nil {
+			add("M %.1f %s", *o.Magnitude, o.MagnitudeType)
+		}
+		if o.Latitude != nil && o.Longitude != nil {
+			add("at %.3f, %.3f", *o.Latitude, *o.Longitude)
+		}
+		if o.Depth != nil {
+			add("depth %.1f km", *o.Depth)
+		}
+		if o.NumStationsUsed != nil {
+			add("%d stations", *o.NumStationsUsed)
+		}
+		if o.AzimuthalGap != nil {
+			add("gap %.0f deg", *o.AzimuthalGap)
+		}
+		if len(o.ReviewStatus) != 0 {
+			add("%s", o.ReviewStatus)
+		}
+	case PRODUCTMOMENTTENSOR, PRODUCTFOCALMECHANISM:
+		m := ExtractMechanism(p)
+		if m.Mw != 0 {
+			add("%s %.2f", cmp.Or(m.MagnitudeType, "Mw"), m.Mw)
+		}
+		if m.PercentDoubleCouple != 0 {
+			add("%.0f%% double couple", m.PercentDoubleCouple)
+		}
+		if len(m.NodalPlanes) != 0 {
+			np := m.NodalPlanes[0]
+			add("strike/dip/rake %.0f/%.0f/%.0f", np.Strike, np.Dip, np.Rake)
+		}
+	case PRODUCTSHAKEMAP:
+		s := p.ShakeMapProperties()
+		if s.MaxMMI != nil {
+			add("max MMI %.1f", *s.MaxMMI)
+		}
+		if s.MaxPGA != nil {
+			add("PGA %.1f %%g", *s.MaxPGA)
+		}
+		if s.MaxPGV != nil {
+			add("PGV %.1f cm/s", *s.MaxPGV)
+		}
+		if len(s.MapStatus) != 0 {
+			add("%s", strings.ToLower(s.MapStatus))
+		}
+	case PRODUCTDYFI:
+		d := p.DYFIProperties()
+		if d.NumResponses != nil {
+			add("%d responses", *d.NumResponses)
+		}
+		if d.MaxMMI != nil {
+			add("max CDI %.1f", *d.MaxMMI)
+		}
+	case PRODUCTLOSSPAGER:
+		l := p.PagerProperties()
+		if len(l.AlertLevel) != 0 {
+			add("alert %s", l.AlertLevel)
+		}
+		if l.MaxMMI != nil {
+			add("max MMI %.1f", *l.MaxMMI)
+		}
+	case PRODUCTFINITEFAULT:
+		f := p.FiniteFaultProperties()
+		if f.DerivedMagnitude != nil {
+			add("%s %.2f", cmp.Or(f.DerivedMagnitudeType, "Mw"), *f.DerivedMagnitude)
+		}
+		if f.ScalarMoment != nil {
+			add("moment %.3g N-m", *f.ScalarMoment)
+		}
+		if f.Depth != nil {
+			add("depth %.1f km", *f.Depth)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,80 @@
+package logic
+
+import "testing"
+
+func TestOriginProperties(t *testing.T) {
+	p := &Product{Type: PRODUCTORIGIN, Properties: map[string]string{
+		"latitude":          "35.77",
+		"longitude":         "-117.6",
+		"depth":             "0",
+		"magnitude":         "6.4",
+		"magnitude-type":    "mww",
+		"num-stations-used": "87",
+		"azimuthal-gap":     "not a number",
+		"review-status":     "reviewed",
+	}}
+
+	o := p.OriginProperties()
+	if o.Latitude == nil || *o.Latitude != 35.77 || o.Depth == nil || *o.Depth != 0 || o.Magnitude == nil || *o.Magnitude != 6.4 {
+		t.Errorf("OriginProperties() location = %v %v %v", o.Latitude, o.Depth, o.Magnitude)
+	}
+	if o.NumStationsUsed == nil || *o.NumStationsUsed != 87 || o.MagnitudeType != "mww" || o.ReviewStatus != "reviewed" {
+		t.Errorf("OriginProperties() = %+v", o)
+	}
+	if o.AzimuthalGap != nil || o.StandardError != nil || o.NumPhasesUsed != nil {
+		t.Errorf("OriginProperties() absent values = %v %v %v; want nil", o.AzimuthalGap, o.StandardError, o.NumPhasesUsed)
+	}
+}
+
+func TestSummaryProperties(t *testing.T) {
+	shakemap := &Product{Properties: map[string]string{"maxmmi": "8.4", "maxpga": "52.1", "map-status": "RELEASED"}}
+	s := shakemap.ShakeMapProperties()
+	if s.MaxMMI == nil || *s.MaxMMI != 8.4 || s.MaxPGA == nil || *s.MaxPGA != 52.1 || s.MaxPGV != nil || s.MapStatus != "RELEASED" {
+		t.Errorf("ShakeMapProperties() = %+v", s)
+	}
+
+	dyfi := &Product{Properties: map[string]string{"maxmmi": "7.1", "num-responses": "24011"}}
+	d := dyfi.DYFIProperties()
+	if d.MaxMMI == nil || *d.MaxMMI != 7.1 || d.NumResponses == nil || *d.NumResponses != 24011 {
+		t.Errorf("DYFIProperties() = %+v", d)
+	}
+
+	pager := &Product{Properties: map[string]string{"alertlevel": "orange"}}
+	if l := pager.PagerProperties(); l.AlertLevel != "orange" || l.MaxMMI != nil {
+		t.Errorf("PagerProperties() = %+v", l)
+	}
+
+	ff := &Product{Properties: map[string]string{"scalar-moment": "4.2e19", "derived-magnitude": "7.0", "derived-magnitude-type": "Mww"}}
+	if f := ff.FiniteFaultProperties(); f.ScalarMoment == nil || *f.ScalarMoment != 4.2e19 || f.DerivedMagnitudeType != "Mww" || f.Depth != nil {
+		t.Errorf("FiniteFaultProperties() = %+v", f)
+	}
+}
+
+type ProductSummaryTest struct {
+	product  Product
+	expected string
+}
+
+func TestProductSummary(t *testing.T) {
+	tests := []ProductSummaryTest{
+		{Product{Type: PRODUCTORIGIN, Properties: map[string]string{
+			"latitude": "35.77", "longitude": "-117.599", "depth": "8", "magnitude": "7.1", "magnitude-type": "mww", "review-status": "reviewed",
+		}}, "M 7.1 mww, at 35.770, -117.599, depth 8.0 km, reviewed"},
+		{Product{Type: PRODUCTSHAKEMAP, Properties: map[string]string{"maxmmi": "8.4", "maxpgv": "60.25", "map-status": "RELEASED"}},
+			"max MMI 8.4, PGV 60.2 cm/s, released"},
+		{Product{Type: PRODUCTDYFI, Properties: map[string]string{"maxmmi": "7.1", "num-responses": "24011"}},
+			"24011 responses, max CDI 7.1"},
+		{Product{Type: PRODUCTLOSSPAGER, Properties: map[string]string{"alertlevel": "orange", "maxmmi": "8.9"}},
+			"alert orange, max MMI 8.9"},
+		{Product{Type: PRODUCTFINITEFAULT, Properties: map[string]string{"derived-magnitude": "7.05", "scalar-moment": "4.2e19"}},
+			"Mw 7.05, moment 4.2e+19 N-m"},
+		{Product{Type: PRODUCTSHAKEMAP}, ""},
+		{Product{Type: "nearby-cities", Properties: map[string]string{"maxmmi": "8.4"}}, ""},
+	}
+
+	for _, test := range tests {
+		if summary := ProductSummary(&test.product); summary != test.expected {
+			t.Errorf("ProductSummary(%s) = %q; want %q", test.product.Type, summary, test.expected)
+		}
+	}
+}
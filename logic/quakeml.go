@@ -0,0 +1,223 @@
+package logic
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Namespaces of a QuakeML 1.2 document.
+const (
+	QUAKEMLNAMESPACE    = "http://quakeml.org/xmlns/quakeml/1.2"
+	QUAKEMLBEDNAMESPACE = "http://quakeml.org/xmlns/bed/1.2"
+)
+
+// QUAKEMLIDPREFIX prefixes the resource identifiers of the written elements,
+// matching the identifiers of the FDSN event service.
+const QUAKEMLIDPREFIX = "quakeml:earthquake.usgs.gov/fdsnws/event/1/query"
+
+// quakeMLEventTypes lists the event types of the QuakeML 1.2 EventType
+// enumeration that USGS event types map onto as is.
+var quakeMLEventTypes = map[string]bool{
+	"not existing": true, "not reported": true, "earthquake": true,
+	"anthropogenic event": true, "collapse": true, "cavity collapse": true,
+	"mine collapse": true, "building collapse": true, "explosion": true,
+	"accidental explosion": true, "chemical explosion": true,
+	"controlled explosion": true, "experimental explosion": true,
+	"industrial explosion": true, "mining explosion": true,
+	"quarry blast": true, "road cut": true, "blasting levee": true,
+	"nuclear explosion": true, "induced or triggered event": true,
+	"rock burst": true, "reservoir loading": true, "fluid injection": true,
+	"fluid extraction": true, "crash": true, "plane crash": true,
+	"train crash": true, "boat crash": true, "other event": true,
+	"atmospheric event": true, "sonic boom": true, "sonic blast": true,
+	"acoustic noise": true, "thunder": true, "avalanche": true,
+	"snow avalanche": true, "debris avalanche": true,
+	"hydroacoustic event": true, "ice quake": true, "slide": true,
+	"landslide": true, "rockslide": true, "meteorite": true, "volcanic eruption": true,
+}
+
+type qmlDocument struct {
+	XMLName         xml.Name           `xml:"q:quakeml"`
+	Namespace       string             `xml:"xmlns:q,attr"`
+	BEDNamespace    string             `xml:"xmlns,attr"`
+	EventParameters qmlEventParameters `xml:"eventParameters"`
+}
+
+type qmlEventParameters struct {
+	PublicID string        `xml:"publicID,attr"`
+	Events   []qmlEventOut `xml:"event"`
+}
+
+type qmlEventOut struct {
+	PublicID             string           `xml:"publicID,attr"`
+	Description          *qmlDescription  `xml:"description,omitempty"`
+	Magnitude            *qmlMagnitude    `xml:"magnitude,omitempty"`
+	Origin               qmlOriginOut     `xml:"origin"`
+	PreferredOriginID    string           `xml:"preferredOriginID"`
+	PreferredMagnitudeID string           `xml:"preferredMagnitudeID,omitempty"`
+	Type                 string           `xml:"type,omitempty"`
+	CreationInfo         *qmlCreationInfo `xml:"creationInfo,omitempty"`
+}
+
+type qmlDescription struct {
+	Text string `xml:"text"`
+	Type string `xml:"type"`
+}
+
+type qmlMagnitude struct {
+	PublicID         string           `xml:"publicID,attr"`
+	Mag              qmlValue         `xml:"mag"`
+	Type             string           `xml:"type,omitempty"`
+	OriginID         string           `xml:"originID"`
+	StationCount     *int             `xml:"stationCount,omitempty"`
+	EvaluationMode   string           `xml:"evaluationMode,omitempty"`
+	EvaluationStatus string           `xml:"evaluationStatus,omitempty"`
+	CreationInfo     *qmlCreationInfo `xml:"creationInfo,omitempty"`
+}
+
+type qmlOriginOut struct {
+	PublicID         string           `xml:"publicID,attr"`
+	Time             qmlTimeValue     `xml:"time"`
+	Longitude        qmlValue         `xml:"longitude"`
+	Latitude         qmlValue         `xml:"latitude"`
+	Depth            *qmlValue        `xml:"depth,omitempty"`
+	Quality          *qmlQuality      `xml:"quality,omitempty"`
+	EvaluationMode   string           `xml:"evaluationMode,omitempty"`
+	EvaluationStatus string           `xml:"evaluationStatus,omitempty"`
+	CreationInfo     *qmlCreationInfo `xml:"creationInfo,omitempty"`
+}
+
+type qmlQuality struct {
+	UsedStationCount *int     `xml:"usedStationCount,omitempty"`
+	StandardError    *float64 `xml:"standardError,omitempty"`
+	AzimuthalGap     *float64 `xml:"azimuthalGap,omitempty"`
+	MinimumDistance  *float64 `xml:"minimumDistance,omitempty"`
+}
+
+type qmlValue struct {
+	Value float64 `xml:"value"`
+}
+
+type qmlTimeValue struct {
+	Value string `xml:"value"`
+}
+
+type qmlCreationInfo struct {
+	AgencyID     string `xml:"agencyID,omitempty"`
+	CreationTime string `xml:"creationTime,omitempty"`
+}
+
+// WriteQuakeML serializes Features as a QuakeML 1.2 document with one event
+// per Feature, each holding its origin and preferred magnitude.
+func WriteQuakeML(w io.Writer, features Features) error {
+	doc := qmlDocument{
+		Namespace:    QUAKEMLNAMESPACE,
+		BEDNamespace: QUAKEMLBEDNAMESPACE,
+		EventParameters: qmlEventParameters{
+			PublicID: QUAKEMLIDPREFIX,
+			Events:   make([]qmlEventOut, 0, len(features)),
+		},
+	}
+	for _, f := range features {
+		doc.EventParameters.Events = append(doc.EventParameters.Events, quakeMLEvent(f))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func quakeMLEvent(f Feature) qmlEventOut {
+	eventID := QUAKEMLIDPREFIX + "?eventid=" + f.Id
+	originID := eventID + "#origin"
+	magnitudeID := eventID + "#magnitude"
+
+	var info *qmlCreationInfo
+	if len(f.Props.Net) != 0 || f.Props.Updated != 0 {
+		info = &qmlCreationInfo{AgencyID: f.Props.Net}
+		if f.Props.Updated != 0 {
+			info.CreationTime = formatMillis(f.Props.Updated)
+		}
+	}
+	mode, status := quakeMLEvaluation(f.Props.Status)
+
+	e := qmlEventOut{
+		PublicID: eventID,
+		Origin: qmlOriginOut{
+			PublicID:         originID,
+			Time:             qmlTimeValue{formatMillis(f.Props.Time)},
+			EvaluationMode:   mode,
+			EvaluationStatus: status,
+			CreationInfo:     info,
+		},
+		PreferredOriginID: originID,
+		CreationInfo:      info,
+	}
+
+	// Events without a magnitude have no magnitude element to prefer.
+	if f.Props.Mag != nil {
+		e.Magnitude = &qmlMagnitude{
+			PublicID:         magnitudeID,
+			Mag:              qmlValue{*f.Props.Mag},
+			Type:             f.Props.MagType,
+			OriginID:         originID,
+			EvaluationMode:   mode,
+			EvaluationStatus: status,
+			CreationInfo:     info,
+		}
+		e.PreferredMagnitudeID = magnitudeID
+	}
+
+	if len(f.Props.Place) != 0 {
+		e.Description = &qmlDescription{Text: f.Props.Place, Type: "earthquake name"}
+	}
+	if t := strings.ToLower(strings.TrimSpace(f.Props.Type)); len(t) != 0 {
+		e.Type = "other event"
+		if quakeMLEventTypes[t] {
+			e.Type = t
+		}
+	}
+
+	if len(f.Geo.Coordinates) >= 2 {
+		e.Origin.Longitude = qmlValue{f.Geo.Coordinates[0]}
+		e.Origin.Latitude = qmlValue{f.Geo.Coordinates[1]}
+	}
+	if len(f.Geo.Coordinates) >= 3 {
+		// QuakeML depths are in meters.
+		e.Origin.Depth = &qmlValue{f.Geo.Coordinates[2] * 1000}
+	}
+
+	q := qmlQuality{
+		UsedStationCount: f.Props.Nst,
+		StandardError:    f.Props.Rms,
+		AzimuthalGap:     f.Props.Gap,
+		MinimumDistance:  f.Props.Dmin,
+	}
+	if q != (qmlQuality{}) {
+		e.Origin.Quality = &q
+	}
+
+	return e
+}
+
+// quakeMLEvaluation maps the review status of an event onto a QuakeML
+// evaluation mode and status.
+func quakeMLEvaluation(status string) (string, string) {
+	switch status {
+	case "reviewed":
+		return "manual", "reviewed"
+	case "automatic":
+		return "automatic", "preliminary"
+	case "deleted":
+		return "manual", "rejected"
+	}
+	return "", ""
+}
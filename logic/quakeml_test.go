@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+type quakeMLTestDocument struct {
+	XMLName xml.Name
+	Events  []struct {
+		PublicID    string `xml:"publicID,attr"`
+		Description string `xml:"description>text"`
+		Magnitude   *struct {
+			Mag  float64 `xml:"mag>value"`
+			Type string  `xml:"type"`
+		} `xml:"magnitude"`
+		Origin struct {
+			Time             string   `xml:"time>value"`
+			Latitude         float64  `xml:"latitude>value"`
+			Longitude        float64  `xml:"longitude>value"`
+			Depth            float64  `xml:"depth>value"`
+			UsedStationCount *int     `xml:"quality>usedStationCount"`
+			AzimuthalGap     *float64 `xml:"quality>azimuthalGap"`
+			EvaluationMode   string   `xml:"evaluationMode"`
+		} `xml:"origin"`
+		PreferredOriginID    string  `xml:"preferredOriginID"`
+		PreferredMagnitudeID *string `xml:"preferredMagnitudeID"`
+		Type                 string  `xml:"type"`
+		AgencyID             string  `xml:"creationInfo>agencyID"`
+	} `xml:"eventParameters>event"`
+}
+
+func TestWriteQuakeML(t *testing.T) {
+	features := Features{
+		{
+			Id:    "ci40012345",
+			Props: Properties{Mag: ptr(3.2), Place: `5 km NNE of "Ridgecrest" & co, CA`, Time: 1718452800123, Updated: 1718452900000, MagType: "ml", Net: "ci", Type: "earthquake", Status: "reviewed", Nst: ptr(42), Gap: ptr(0.0)},
+			Geo:   Geometry{Coordinates: []float64{-117.6, 35.7, 8.2}},
+		},
+		{
+			Id:    "ak0241",
+			Props: Properties{Type: "glacial quake", Status: "automatic"},
+			Geo:   Geometry{Coordinates: []float64{-150.1, 61.2, 30}},
+		},
+	}
+
+	var b strings.Builder
+	if err := WriteQuakeML(&b, features); err != nil {
+		t.Fatalf("WriteQuakeML() = %v", err)
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, xml.Header+`<q:quakeml xmlns:q="`+QUAKEMLNAMESPACE+`" xmlns="`+QUAKEMLBEDNAMESPACE+`">`) {
+		t.Errorf("WriteQuakeML() header = %q", out[:min(len(out), 200)])
+	}
+
+	var doc quakeMLTestDocument
+	if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("WriteQuakeML() wrote invalid XML: %v\n%s", err, out)
+	}
+	if doc.XMLName.Space != QUAKEMLNAMESPACE || doc.XMLName.Local != "quakeml" || len(doc.Events) != 2 {
+		t.Fatalf("WriteQuakeML() = %v with %d events", doc.XMLName, len(doc.Events))
+	}
+
+	e := doc.Events[0]
+	if e.PublicID != QUAKEMLIDPREFIX+"?eventid=ci40012345" || e.PreferredOriginID != e.PublicID+"#origin" ||
+		e.Description != `5 km NNE of "Ridgecrest" & co, CA` || e.Type != "earthquake" || e.AgencyID != "ci" {
+		t.Errorf("WriteQuakeML() event = %+v", e)
+	}
+	if e.Magnitude == nil || e.Magnitude.Mag != 3.2 || e.Magnitude.Type != "ml" ||
+		e.PreferredMagnitudeID == nil || *e.PreferredMagnitudeID != e.PublicID+"#magnitude" {
+		t.Errorf("WriteQuakeML() magnitude = %+v %v", e.Magnitude, e.PreferredMagnitudeID)
+	}
+	o := e.Origin
+	if o.Time != "2024-06-15T12:00:00.123Z" || o.Latitude != 35.7 || o.Longitude != -117.6 || o.Depth != 8200 ||
+		o.UsedStationCount == nil || *o.UsedStationCount != 42 || o.AzimuthalGap == nil || *o.AzimuthalGap != 0 || o.EvaluationMode != "manual" {
+		t.Errorf("WriteQuakeML() origin = %+v", o)
+	}
+
+	if e := doc.Events[1]; e.Type != "other event" || e.Description != "" || e.Origin.EvaluationMode != "automatic" ||
+		e.Magnitude != nil || e.PreferredMagnitudeID != nil || e.Origin.UsedStationCount != nil {
+		t.Errorf("WriteQuakeML() second event without a magnitude = %+v", e)
+	}
+}
@@ -0,0 +1,141 @@
+package logic
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrFlagIntervalOption = errors.New("--interval option invalid")
+
+const RTMAXDURATION = 30 * 24 * time.Hour
+
+// rtMagFeeds lists the magnitude thresholds of the summary feeds from the
+// highest down, paired with the feed name.
+var rtMagFeeds = []struct {
+	threshold float64
+	feed      string
+}{
+	{4.5, "4.5"},
+	{2.5, "2.5"},
+	{1.0, "1.0"},
+}
+
+// rtTimeFeeds lists the time ranges of the summary feeds from the shortest up,
+// paired with the feed name.
+var rtTimeFeeds = []struct {
+	length time.Duration
+	feed   string
+}{
+	{time.Hour, "hour"},
+	{24 * time.Hour, "day"},
+	{7 * 24 * time.Hour, "week"},
+	{RTMAXDURATION, "month"},
+}
+
+// durationPattern matches durations such as "90m", "36h", "3d" or "2w".
+var durationPattern = regexp.MustCompile(`^(\d+)\s*(m|h|d|w)$`)
+
+// resolveRTMag maps a magnitude flag value onto the summary feed covering it.
+// Named options map onto their feed. Any other number maps onto the feed with
+// the highest threshold at or below it and is returned as the minimum
+// magnitude to filter the feed on locally.
+func resolveRTMag(magFlag string) (string, string, error) {
+	switch magFlag {
+	case "major":
+		return "significant", "", nil
+	case "all":
+		return "all", "", nil
+	}
+
+	mag, err := strconv.ParseFloat(strings.TrimSpace(magFlag), 64)
+	if err != nil {
+		return "", "", ErrFlagMagOption
+	}
+
+	for _, m := range rtMagFeeds {
+		if mag == m.threshold {
+			return m.feed, "", nil
+		}
+		if mag > m.threshold {
+			return m.feed, formatFloat(mag), nil
+		}
+	}
+	return "all", formatFloat(mag), nil
+}
+
+// resolveRTTime maps a time flag value onto the shortest summary feed
+// covering it. Named options map onto their feed. A duration of up to 30 days
+// that is not the length of a feed is returned to filter the feed on locally.
+func resolveRTTime(timeFlag string) (string, time.Duration, error) {
+	for _, t := range rtTimeFeeds {
+		if timeFlag == t.feed {
+			return t.feed, 0, nil
+		}
+	}
+
+	match := durationPattern.FindStringSubmatch(strings.TrimSpace(timeFlag))
+	if match == nil {
+		return "", 0, ErrFlagTimeOption
+	}
+
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n == 0 {
+		return "", 0, ErrFlagTimeOption
+	}
+
+	units := map[string]time.Duration{
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	d := time.Duration(n) * units[match[2]]
+
+	for _, t := range rtTimeFeeds {
+		if d == t.length {
+			return t.feed, 0, nil
+		}
+		if d < t.length {
+			return t.feed, d, nil
+		}
+	}
+	return "", 0, ErrFlagTimeOption
+}
+
+// ExtractRTFilters returns the Filters that narrow the summary feed selected
+// by ExtractRTParams down to a magnitude threshold or duration that has no
+// feed of its own.
+func ExtractRTFilters(magFlag, timeFlag string) ([]Filter, error) {
+	var filters []Filter
+
+	_, minMag, err := resolveRTMag(magFlag)
+	if err != nil {
+		return nil, err
+	}
+	if len(minMag) != 0 {
+		filters = append(filters, rangeFilter(minMag, "", magnitude))
+	}
+
+	_, d, err := resolveRTTime(timeFlag)
+	if err != nil {
+		return nil, err
+	}
+	if d != 0 {
+		since := timeNow().Add(-d).UnixMilli()
+		filters = append(filters, func(f Feature) bool {
+			return f.Props.Time >= since
+		})
+	}
+
+	return filters, nil
+}
+
+func magnitude(f Feature) (float64, bool) {
+	if f.Props.Mag == nil {
+		return 0, false
+	}
+	return *f.Props.Mag, true
+}
@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+type RTParamsTest struct {
+	mag, dur, out string
+	err           error
+}
+
+type RTFiltersTest struct {
+	mag, dur string
+	out      []string
+	err      error
+}
+
+func TestExtractRTParams(t *testing.T) {
+	pTests := []RTParamsTest{
+		{"major", "month", RTENDPOINT + "/significant_month.geojson", nil},
+		{"4.5", "hour", RTENDPOINT + "/4.5_hour.geojson", nil},
+		{"3.0", "3d", RTENDPOINT + "/2.5_week.geojson", nil},
+		{"6", "36h", RTENDPOINT + "/4.5_week.geojson", nil},
+		{"0.5", "90m", RTENDPOINT + "/all_day.geojson", nil},
+		{"1", "2w", RTENDPOINT + "/1.0_month.geojson", nil},
+		{"all", "30d", RTENDPOINT + "/all_month.geojson", nil},
+		{"2.5", "1d", RTENDPOINT + "/2.5_day.geojson", nil},
+		{"big", "day", "", ErrFlagMagOption},
+		{"2.5", "31d", "", ErrFlagTimeOption},
+		{"2.5", "0h", "", ErrFlagTimeOption},
+		{"2.5", "year", "", ErrFlagTimeOption},
+	}
+
+	for _, test := range pTests {
+		endpoint, err := ExtractRTParams("json", test.mag, test.dur)
+		if endpoint != test.out || err != test.err {
+			t.Errorf("ExtractRTParams(json, %q, %q) = %v %v; want %v %v", test.mag, test.dur, endpoint, err, test.out, test.err)
+		}
+	}
+}
+
+func TestExtractRTFilters(t *testing.T) {
+	now := time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	features := Features{
+		{Id: "a", Props: Properties{Mag: ptr(3.4), Time: now.Add(-2 * time.Hour).UnixMilli()}},
+		{Id: "b", Props: Properties{Mag: ptr(2.7), Time: now.Add(-30 * time.Hour).UnixMilli()}},
+		{Id: "c", Props: Properties{Mag: ptr(5.1), Time: now.Add(-80 * time.Hour).UnixMilli()}},
+	}
+
+	fTests := []RTFiltersTest{
+		{"2.5", "week", []string{"a", "b", "c"}, nil},
+		{"3.0", "week", []string{"a", "c"}, nil},
+		{"2.5", "36h", []string{"a", "b"}, nil},
+		{"3.0", "3d", []string{"a"}, nil},
+		{"major", "day", []string{"a", "b", "c"}, nil},
+		{"2.5", "40d", nil, ErrFlagTimeOption},
+	}
+
+	for _, test := range fTests {
+		filters, err := ExtractRTFilters(test.mag, test.dur)
+
+		var ids []string
+		if err == nil {
+			for _, f := range FilterFeatures(features, filters...) {
+				ids = append(ids, f.Id)
+			}
+		}
+		if !slices.Equal(ids, test.out) || err != test.err {
+			t.Errorf("ExtractRTFilters(%q, %q) = %v %v; want %v %v", test.mag, test.dur, ids, err, test.out, test.err)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package logic
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrFlagBBoxOption = errors.New("--bbox option invalid")
+var ErrFlagRadiusOption = errors.New("--radius option invalid")
+var ErrFlagRegionOption = errors.New("--bbox and --radius options are mutually exclusive")
+
+const (
+	EARTHRADIUSKM = 6371.0
+	KMPERDEGREE   = EARTHRADIUSKM * math.Pi / 180
+)
+
+// BBox is a geographic rectangle bounded by latitude and longitude in decimal
+// degrees. A box whose MinLon is greater than its MaxLon crosses the
+// antimeridian.
+type BBox struct {
+	MinLat float64
+	MaxLat float64
+	MinLon float64
+	MaxLon float64
+}
+
+// ParseBBox validates a bounding box flag value of the form
+// "minlat,maxlat,minlon,maxlon". An empty flag value returns a nil BBox.
+func ParseBBox(bFlag string) (*BBox, error) {
+	if len(strings.TrimSpace(bFlag)) == 0 {
+		return nil, nil
+	}
+
+	fields := strings.Split(bFlag, ",")
+	if len(fields) != 4 {
+		return nil, ErrFlagBBoxOption
+	}
+
+	var vals [4]float64
+	for i, field := range fields {
+		val, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, ErrFlagBBoxOption
+		}
+		vals[i] = val
+	}
+
+	b := &BBox{MinLat: vals[0], MaxLat: vals[1], MinLon: vals[2], MaxLon: vals[3]}
+	if !validLatitude(b.MinLat) || !validLatitude(b.MaxLat) || b.MinLat > b.MaxLat {
+		return nil, ErrFlagBBoxOption
+	}
+	if !validLongitude(b.MinLon) || !validLongitude(b.MaxLon) || b.MinLon == b.MaxLon {
+		return nil, ErrFlagBBoxOption
+	}
+	return b, nil
+}
+
+// CrossesAntimeridian reports whether the box spans the 180th meridian.
+func (b *BBox) CrossesAntimeridian() bool {
+	return b.MinLon > b.MaxLon
+}
+
+// Contains reports whether the point lies within the box.
+func (b *BBox) Contains(lat, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return lon >= b.MinLon || lon <= b.MaxLon
+	}
+	return lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// setValues adds the FDSN rectangle parameters for the box. The FDSN service
+// accepts longitudes in [-360, 360] so a box crossing the antimeridian is
+// expressed by unwrapping its eastern edge past 180 degrees.
+func (b *BBox) setValues(v url.Values) {
+	maxLon := b.MaxLon
+	if b.CrossesAntimeridian() {
+		maxLon += 360
+	}
+	v.Set("minlatitude", formatFloat(b.MinLat))
+	v.Set("maxlatitude", formatFloat(b.MaxLat))
+	v.Set("minlongitude", formatFloat(b.MinLon))
+	v.Set("maxlongitude", formatFloat(maxLon))
+}
+
+// Circle is a circular region around a point. The radii are expressed in
+// Unit, either "km" or "deg". A zero MinRadius leaves the inner bound open.
+type Circle struct {
+	Lat       float64
+	Lon       float64
+	MinRadius float64
+	MaxRadius float64
+	Unit      string
+}
+
+// UsesPlace reports whether a radius flag value of the form "place,radius"
+// refers to a named place rather than giving coordinates.
+func UsesPlace(rFlag string) bool {
+	return len(strings.Split(rFlag, ",")) == 2
+}
+
+// ParseCircle validates a radius flag value of the form "lat,lon,radius" or
+// "place,radius" where place is a key of places. The radius is a distance
+// such as "150km", "150" (kilometers) or "2deg", or an inner and outer
+// distance separated by a dash, e.g. "50km-150km". An empty flag value returns
+// a nil Circle.
+func ParseCircle(rFlag string, places map[string]Place) (*Circle, error) {
+	if len(strings.TrimSpace(rFlag)) == 0 {
+		return nil, nil
+	}
+
+	c := new(Circle)
+	fields := strings.Split(rFlag, ",")
+	switch len(fields) {
+	case 2:
+		place, hasKey := places[strings.TrimSpace(fields[0])]
+		if !hasKey {
+			return nil, ErrFlagRadiusOption
+		}
+		c.Lat, c.Lon = place.Lat, place.Lon
+	case 3:
+		lat, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		if err != nil {
+			return nil, ErrFlagRadiusOption
+		}
+		lon, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil {
+			return nil, ErrFlagRadiusOption
+		}
+		c.Lat, c.Lon = lat, lon
+	default:
+		return nil, ErrFlagRadiusOption
+	}
+
+	if !validLatitude(c.Lat) || !validLongitude(c.Lon) {
+		return nil, ErrFlagRadiusOption
+	}
+
+	radii := strings.Split(fields[len(fields)-1], "-")
+	if len(radii) > 2 {
+		return nil, ErrFlagRadiusOption
+	}
+
+	maxRadius, unit, err := parseDistance(radii[len(radii)-1])
+	if err != nil {
+		return nil, err
+	}
+	c.MaxRadius, c.Unit = maxRadius, unit
+
+	if len(radii) == 2 {
+		minRadius, minUnit, err := parseDistance(radii[0])
+		if err != nil {
+			return nil, err
+		}
+		c.MinRadius = convertDistance(minRadius, minUnit, unit)
+	}
+
+	if c.MaxRadius <= 0 || c.MinRadius >= c.MaxRadius {
+		return nil, ErrFlagRadiusOption
+	}
+	if unit == "deg" && c.MaxRadius > 180 {
+		return nil, ErrFlagRadiusOption
+	}
+	return c, nil
+}
+
+// parseDistance splits a distance such as "150km" or "2deg" into its value and
+// unit. A distance without a unit is in kilometers.
+func parseDistance(dist string) (float64, string, error) {
+	dist = strings.TrimSpace(dist)
+	unit := "km"
+	if strings.HasSuffix(dist, "deg") {
+		unit = "deg"
+	}
+	dist = strings.TrimSpace(strings.TrimSuffix(dist, unit))
+
+	val, err := strconv.ParseFloat(dist, 64)
+	if err != nil || val < 0 {
+		return 0, "", ErrFlagRadiusOption
+	}
+	return val, unit, nil
+}
+
+func convertDistance(dist float64, from, to string) float64 {
+	switch {
+	case from == to:
+		return dist
+	case to == "deg":
+		return dist / KMPERDEGREE
+	default:
+		return dist * KMPERDEGREE
+	}
+}
+
+// Contains reports whether the point lies between the inner and outer radius
+// of the circle.
+func (c *Circle) Contains(lat, lon float64) bool {
+	dist := convertDistance(angularDistance(c.Lat, c.Lon, lat, lon), "deg", c.Unit)
+	return dist >= c.MinRadius && dist <= c.MaxRadius
+}
+
+// angularDistance returns the great-circle distance in degrees between two
+// points using the haversine formula.
+func angularDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := phi2 - phi1
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	h := math.Pow(math.Sin(dPhi/2), 2) + math.Cos(phi1)*math.Cos(phi2)*math.Pow(math.Sin(dLambda/2), 2)
+	return 2 * math.Asin(math.Sqrt(min(h, 1))) * 180 / math.Pi
+}
+
+// setValues adds the FDSN circle parameters for the region. The outer radius
+// is sent in the unit it was given in while the inner radius is always sent
+// in degrees.
+func (c *Circle) setValues(v url.Values) {
+	v.Set("latitude", formatFloat(c.Lat))
+	v.Set("longitude", formatFloat(c.Lon))
+	if c.Unit == "deg" {
+		v.Set("maxradius", formatFloat(c.MaxRadius))
+	} else {
+		v.Set("maxradiuskm", formatFloat(c.MaxRadius))
+	}
+	if c.MinRadius > 0 {
+		v.Set("minradius", formatFloat(convertDistance(c.MinRadius, c.Unit, "deg")))
+	}
+}
+
+func validLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+func validLongitude(lon float64) bool {
+	return lon >= -180 && lon <= 180
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,158 @@
+package logic
+
+import (
+	"net/url"
+	"testing"
+)
+
+type BBoxTest struct {
+	in  string
+	out *BBox
+	err error
+}
+
+type BBoxValuesTest struct {
+	in                             BBox
+	minLat, maxLat, minLon, maxLon string
+}
+
+func TestParseBBox(t *testing.T) {
+	bTests := []BBoxTest{
+		{"32,42,-125,-114", &BBox{32, 42, -125, -114}, nil},
+		{" 32.5, 42.25 , -125 ,-114 ", &BBox{32.5, 42.25, -125, -114}, nil},
+		{"-60,-10,170,-170", &BBox{-60, -10, 170, -170}, nil},
+		{"-90,90,-180,180", &BBox{-90, 90, -180, 180}, nil},
+		{"", nil, nil},
+		{"42,32,-125,-114", nil, ErrFlagBBoxOption},
+		{"32,91,-125,-114", nil, ErrFlagBBoxOption},
+		{"32,42,-181,-114", nil, ErrFlagBBoxOption},
+		{"32,42,10,10", nil, ErrFlagBBoxOption},
+		{"32,42,-125", nil, ErrFlagBBoxOption},
+		{"32,42,a,-114", nil, ErrFlagBBoxOption},
+	}
+
+	for _, test := range bTests {
+		b, err := ParseBBox(test.in)
+		if err != test.err || (b == nil) != (test.out == nil) || (b != nil && *b != *test.out) {
+			t.Errorf("ParseBBox(%q) = %v %v; want %v %v", test.in, b, err, test.out, test.err)
+		}
+	}
+}
+
+func TestBBoxSetValues(t *testing.T) {
+	vTests := []BBoxValuesTest{
+		{BBox{32, 42, -125, -114}, "32", "42", "-125", "-114"},
+		{BBox{-60, -10, 170, -170}, "-60", "-10", "170", "190"},
+		{BBox{-0.5, 0.5, 179.5, -179.5}, "-0.5", "0.5", "179.5", "180.5"},
+	}
+
+	for _, test := range vTests {
+		v := url.Values{}
+		test.in.setValues(v)
+		if v.Get("minlatitude") != test.minLat || v.Get("maxlatitude") != test.maxLat ||
+			v.Get("minlongitude") != test.minLon || v.Get("maxlongitude") != test.maxLon {
+			t.Errorf("setValues(%v) = %v; want %v %v %v %v", test.in, v, test.minLat, test.maxLat, test.minLon, test.maxLon)
+		}
+	}
+}
+
+type CircleTest struct {
+	in  string
+	out *Circle
+	err error
+}
+
+func TestParseCircle(t *testing.T) {
+	places := map[string]Place{"plant": {Lat: 35.2, Lon: -120.85}}
+	cTests := []CircleTest{
+		{"37.8,-122.4,150km", &Circle{37.8, -122.4, 0, 150, "km"}, nil},
+		{"37.8,-122.4,150", &Circle{37.8, -122.4, 0, 150, "km"}, nil},
+		{" 37.8 , -122.4 , 2deg ", &Circle{37.8, -122.4, 0, 2, "deg"}, nil},
+		{"37.8,-122.4,1deg-2deg", &Circle{37.8, -122.4, 1, 2, "deg"}, nil},
+		{"plant,50km", &Circle{35.2, -120.85, 0, 50, "km"}, nil},
+		{"", nil, nil},
+		{"refinery,50km", nil, ErrFlagRadiusOption},
+		{"37.8,-122.4", nil, ErrFlagRadiusOption},
+		{"97.8,-122.4,150km", nil, ErrFlagRadiusOption},
+		{"37.8,-122.4,0km", nil, ErrFlagRadiusOption},
+		{"37.8,-122.4,150km-50km", nil, ErrFlagRadiusOption},
+		{"37.8,-122.4,200deg", nil, ErrFlagRadiusOption},
+		{"37.8,-122.4,150mi", nil, ErrFlagRadiusOption},
+	}
+
+	for _, test := range cTests {
+		c, err := ParseCircle(test.in, places)
+		if err != test.err || (c == nil) != (test.out == nil) || (c != nil && *c != *test.out) {
+			t.Errorf("ParseCircle(%q) = %v %v; want %v %v", test.in, c, err, test.out, test.err)
+		}
+	}
+}
+
+func TestCircleSetValues(t *testing.T) {
+	v := url.Values{}
+	c := Circle{Lat: 37.8, Lon: -122.4, MinRadius: 2 * KMPERDEGREE, MaxRadius: 150, Unit: "km"}
+	c.setValues(v)
+	if v.Get("latitude") != "37.8" || v.Get("longitude") != "-122.4" ||
+		v.Get("maxradiuskm") != "150" || v.Get("minradius") != "2" || v.Has("maxradius") {
+		t.Errorf("setValues(%v) = %v", c, v)
+	}
+
+	v = url.Values{}
+	c = Circle{Lat: 37.8, Lon: -122.4, MaxRadius: 2, Unit: "deg"}
+	c.setValues(v)
+	if v.Get("maxradius") != "2" || v.Has("maxradiuskm") || v.Has("minradius") {
+		t.Errorf("setValues(%v) = %v", c, v)
+	}
+}
+
+func TestBBoxContains(t *testing.T) {
+	b := BBox{-60, -10, 170, -170}
+	cTests := []ContainsTest{
+		{-20, 175, true},
+		{-20, -175, true},
+		{-20, 180, true},
+		{-20, 0, false},
+		{-5, 175, false},
+	}
+
+	for _, test := range cTests {
+		if in := b.Contains(test.lat, test.lon); in != test.out {
+			t.Errorf("%v.Contains(%v, %v) = %v; want %v", b, test.lat, test.lon, in, test.out)
+		}
+	}
+}
+
+func TestCircleContains(t *testing.T) {
+	c := Circle{Lat: 0, Lon: 179, MinRadius: 100, MaxRadius: 300, Unit: "km"}
+	cTests := []ContainsTest{
+		{0, -179, true},
+		{0, 179.5, false},
+		{2, 179, true},
+		{0, 175, false},
+	}
+
+	for _, test := range cTests {
+		if in := c.Contains(test.lat, test.lon); in != test.out {
+			t.Errorf("%v.Contains(%v, %v) = %v; want %v", c, test.lat, test.lon, in, test.out)
+		}
+	}
+}
+
+type UsesPlaceTest struct {
+	in  string
+	out bool
+}
+
+func TestUsesPlace(t *testing.T) {
+	uTests := []UsesPlaceTest{
+		{"plant,50km", true},
+		{"37.8,-122.4,150km", false},
+		{"", false},
+	}
+
+	for _, test := range uTests {
+		if got := UsesPlace(test.in); got != test.out {
+			t.Errorf("UsesPlace(%q) = %v; want %v", test.in, got, test.out)
+		}
+	}
+}
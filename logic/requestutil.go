@@ -1,11 +1,14 @@
 package logic
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,6 +17,13 @@ var ErrFlagMagOption = errors.New("--magnitude option invalid")
 var ErrFlagTimeOption = errors.New("--time interval option invalid")
 var ErrFlagFormatOption = errors.New("--output format option invalid")
 var ErrEventIdInvalid = errors.New("eventid invalid")
+var ErrFlagDepthOption = errors.New("--depth option invalid")
+var ErrFlagLimitOption = errors.New("--limit option invalid")
+var ErrFlagOffsetOption = errors.New("--offset option invalid")
+var ErrRequestStatus = errors.New("request failed")
+var ErrFlagCatalogOption = errors.New("--catalog option invalid")
+var ErrFlagContributorOption = errors.New("--contributor option invalid")
+var ErrFlagUpdatedOption = errors.New("--updated-after option invalid")
 
 const (
 	RTENDPOINT   = "https://earthquake.usgs.gov/earthquakes/feed/v1.0/summary"
@@ -21,39 +31,21 @@ const (
 	ALPHABET     = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	NONALPHABET  = "\"&*?-.+-^%(){}[]_@:|\\!~`,"
 	DIGITS       = "0123456789"
+	FDSNMAXLIMIT = 20000
 )
 
 // ExtractRTParams parses the user flag values and returns a complete URL to
-// send to the server.
+// send to the server. Magnitudes and durations without a feed of their own
+// select the smallest feed covering them, see ExtractRTFilters.
 func ExtractRTParams(formatFlag, magFlag, timeFlag string) (string, error) {
-	var magRange string
-	switch magFlag {
-	case "major":
-		magRange = "significant"
-	case "4.5":
-		magRange = "4.5"
-	case "2.5":
-		magRange = "2.5"
-	case "1.0":
-		magRange = "1.0"
-	case "all":
-		magRange = "all"
-	default:
-		return "", ErrFlagMagOption
-	}
-
-	var timeRange string
-	switch timeFlag {
-	case "hour":
-		timeRange = "hour"
-	case "day":
-		timeRange = "day"
-	case "week":
-		timeRange = "week"
-	case "month":
-		timeRange = "month"
-	default:
-		return "", ErrFlagTimeOption
+	magRange, _, err := resolveRTMag(magFlag)
+	if err != nil {
+		return "", err
+	}
+
+	timeRange, _, err := resolveRTTime(timeFlag)
+	if err != nil {
+		return "", err
 	}
 
 	var fileSuffix string
@@ -76,9 +68,22 @@ func ExtractRTParams(formatFlag, magFlag, timeFlag string) (string, error) {
 	return fullURL, nil
 }
 
-// RequestContent performs the GET request for the resource.
+// RequestContent performs the GET request for the resource. A response with a
+// status other than 2xx returns an ErrRequestStatus error holding the server's
+// message.
 func RequestContent(apiPath string) ([]byte, error) {
-	response, err := http.Get(apiPath)
+	return RequestContentContext(context.Background(), apiPath)
+}
+
+// RequestContentContext performs the GET request for the resource, giving up
+// once ctx is done.
+func RequestContentContext(ctx context.Context, apiPath string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, apiPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := http.DefaultClient.Do(request)
 	if err != nil {
 		return nil, err
 	}
@@ -88,17 +93,42 @@ func RequestContent(apiPath string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: %s: %s", ErrRequestStatus, response.Status, strings.TrimSpace(string(bContent)))
+	}
 	return bContent, nil
 }
 
+// FDSNFlags holds the user input flag values that make up an FDSN request.
+type FDSNFlags struct {
+	Mag      string
+	Format   string
+	DateTime string
+	Updated  string
+	OrderBy  string
+	Limit    int
+	Offset   int
+
+	Catalog     string
+	Contributor string
+	FilterFlags
+}
+
 // ExtractFDSNParams resolves user input flag values and pairs them with the
 // endpoint method to return a complete URL for a request.
-func ExtractFDSNParams(endCmd, magFlag, formatFlag, dateTimeFlag string) (string, error) {
+func ExtractFDSNParams(endCmd string, flags FDSNFlags) (string, error) {
 	v := url.Values{}
 
-	switch formatFlag {
+	switch flags.Format {
 	case "table":
 		fallthrough
+	case "kml":
+		fallthrough
+	case "kmz":
+		fallthrough
+	case "ndjson":
+		fallthrough
 	case "geojson":
 		fallthrough
 	case "json":
@@ -107,11 +137,15 @@ func ExtractFDSNParams(endCmd, magFlag, formatFlag, dateTimeFlag string) (string
 		v.Set("format", "text")
 	case "csv":
 		v.Set("format", "csv")
+	case "quakeml":
+		fallthrough
+	case "xml":
+		v.Set("format", "xml")
 	default:
 		return "", ErrFlagFormatOption
 	}
 
-	from, to, err := extractMagnitude(magFlag)
+	from, to, err := extractMagnitude(flags.Mag)
 	if err != nil {
 		return "", err
 	}
@@ -124,7 +158,7 @@ func ExtractFDSNParams(endCmd, magFlag, formatFlag, dateTimeFlag string) (string
 		v.Set("maxmagnitude", to)
 	}
 
-	startTime, endTime, err := extractTime(dateTimeFlag)
+	startTime, endTime, err := extractTime(flags.DateTime)
 	if err != nil {
 		return "", err
 	}
@@ -137,6 +171,60 @@ func ExtractFDSNParams(endCmd, magFlag, formatFlag, dateTimeFlag string) (string
 		v.Set("endtime", endTime)
 	}
 
+	if len(strings.TrimSpace(flags.Updated)) != 0 {
+		updatedAfter, err := parseTimeBound(strings.TrimSpace(flags.Updated))
+		if err != nil {
+			return "", ErrFlagUpdatedOption
+		}
+		v.Set("updatedafter", updatedAfter)
+	}
+
+	if err := flags.FilterFlags.setValues(v); err != nil {
+		return "", err
+	}
+
+	catalog, err := validateSource(flags.Catalog, ErrFlagCatalogOption)
+	if err != nil {
+		return "", err
+	}
+
+	if len(catalog) != 0 {
+		v.Set("catalog", catalog)
+	}
+
+	contributor, err := validateSource(flags.Contributor, ErrFlagContributorOption)
+	if err != nil {
+		return "", err
+	}
+
+	if len(contributor) != 0 {
+		v.Set("contributor", contributor)
+	}
+
+	if err := ValidateOrder(flags.OrderBy); err != nil {
+		return "", err
+	}
+
+	if len(flags.OrderBy) != 0 {
+		v.Set("orderby", flags.OrderBy)
+	}
+
+	if flags.Limit < 0 || flags.Limit > FDSNMAXLIMIT {
+		return "", ErrFlagLimitOption
+	}
+
+	if flags.Limit != 0 {
+		v.Set("limit", strconv.Itoa(flags.Limit))
+	}
+
+	if flags.Offset < 0 {
+		return "", ErrFlagOffsetOption
+	}
+
+	if flags.Offset != 0 {
+		v.Set("offset", strconv.Itoa(flags.Offset))
+	}
+
 	// Prepare URL Request
 	fullURL, err := url.Parse(FDSNENDPOINT)
 	if err != nil {
@@ -202,6 +290,62 @@ func extractMagnitude(mFlag string) (string, string, error) {
 	return "", "", ErrFlagMagOption
 }
 
+// extractRange parses a numeric range flag value written in the magnitude
+// grammar: "low,high", ">low", "<high" or an exact value. Unlike magnitudes,
+// values may be negative so a dash does not separate the bounds. errOpt is
+// returned for any malformed value.
+func extractRange(rFlag string, errOpt error) (string, string, error) {
+	rFlag = strings.TrimSpace(rFlag)
+	if len(rFlag) == 0 {
+		return "", "", nil
+	}
+
+	var lower, upper string
+	switch {
+	case strings.Contains(rFlag, ","):
+		fields := strings.Split(rFlag, ",")
+		if len(fields) != 2 {
+			return "", "", errOpt
+		}
+		lower = strings.TrimSpace(fields[0])
+		upper = strings.TrimSpace(fields[1])
+	case strings.HasPrefix(rFlag, ">"):
+		lower = strings.TrimSpace(strings.TrimPrefix(rFlag, ">"))
+	case strings.HasPrefix(rFlag, "<"):
+		upper = strings.TrimSpace(strings.TrimPrefix(rFlag, "<"))
+	default:
+		lower, upper = rFlag, rFlag
+	}
+
+	for _, bound := range []string{lower, upper} {
+		if len(bound) == 0 {
+			continue
+		}
+		if _, err := strconv.ParseFloat(bound, 64); err != nil {
+			return "", "", errOpt
+		}
+	}
+
+	if len(lower) == 0 && len(upper) == 0 {
+		return "", "", errOpt
+	}
+	return lower, upper, nil
+}
+
+// timeNow is replaced in tests to resolve relative times deterministically.
+var timeNow = time.Now
+
+// relativePattern matches durations before now such as "-7d" or "-36h".
+var relativePattern = regexp.MustCompile(`^-(\d+)\s*(s|m|h|d|w)$`)
+
+// lastPattern matches durations before now such as "last 2 weeks" or
+// "last day".
+var lastPattern = regexp.MustCompile(`^last\s+(?:(\d+)\s+)?(minute|hour|day|week|month|year)s?$`)
+
+// extractTime parses a time range flag value into UTC start and end times.
+// Either side of a "start,end" pair may be left empty for an open-ended range,
+// and either side may be a time relative to now. A relative time given on its
+// own is the start of a range that ends now.
 func extractTime(tFlag string) (string, string, error) {
 
 	if len(tFlag) == 0 {
@@ -210,19 +354,19 @@ func extractTime(tFlag string) (string, string, error) {
 
 	if strings.Contains(tFlag, ",") {
 		fields := strings.Split(tFlag, ",")
+		if len(fields) != 2 {
+			return "", "", ErrFlagTimeOption
+		}
+
 		begin := strings.TrimSpace(fields[0])
 		end := strings.TrimSpace(fields[1])
 
-		if len(begin) == 0 && len(end) == 0 {
-			return "", "", nil
-		}
-
-		validBegin, err := parseTime(begin)
+		validBegin, err := parseTimeBound(begin)
 		if err != nil {
 			return "", "", ErrFlagTimeOption
 		}
 
-		validEnd, err := parseTime(end)
+		validEnd, err := parseTimeBound(end)
 		if err != nil {
 			return "", "", ErrFlagTimeOption
 		}
@@ -230,19 +374,94 @@ func extractTime(tFlag string) (string, string, error) {
 		return validBegin, validEnd, nil
 	}
 
-	return "", "", ErrFlagTimeOption
+	validBegin, err := parseRelativeTime(strings.TrimSpace(tFlag))
+	if err != nil {
+		return "", "", ErrFlagTimeOption
+	}
+	return validBegin, "", nil
+}
+
+// parseTimeBound parses one side of a time range, which is either empty, an
+// absolute time or a time relative to now.
+func parseTimeBound(timeStr string) (string, error) {
+	if len(timeStr) == 0 {
+		return "", nil
+	}
+
+	if relTime, err := parseRelativeTime(timeStr); err == nil {
+		return relTime, nil
+	}
+	return parseTime(timeStr)
 }
 
+// parseRelativeTime resolves a time relative to now, such as "-7d", "-36h" or
+// "last 2 weeks", into a UTC time.
+func parseRelativeTime(timeStr string) (string, error) {
+	timeStr = strings.ToLower(timeStr)
+	now := timeNow().UTC()
+
+	if match := relativePattern.FindStringSubmatch(timeStr); match != nil {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			return "", ErrFlagTimeOption
+		}
+
+		units := map[string]time.Duration{
+			"s": time.Second,
+			"m": time.Minute,
+			"h": time.Hour,
+			"d": 24 * time.Hour,
+			"w": 7 * 24 * time.Hour,
+		}
+		return now.Add(-time.Duration(n) * units[match[2]]).Format(time.RFC3339), nil
+	}
+
+	if match := lastPattern.FindStringSubmatch(strings.Join(strings.Fields(timeStr), " ")); match != nil {
+		n := 1
+		if len(match[1]) != 0 {
+			var err error
+			if n, err = strconv.Atoi(match[1]); err != nil {
+				return "", ErrFlagTimeOption
+			}
+		}
+
+		var t time.Time
+		switch match[2] {
+		case "minute":
+			t = now.Add(-time.Duration(n) * time.Minute)
+		case "hour":
+			t = now.Add(-time.Duration(n) * time.Hour)
+		case "day":
+			t = now.AddDate(0, 0, -n)
+		case "week":
+			t = now.AddDate(0, 0, -7*n)
+		case "month":
+			t = now.AddDate(0, -n, 0)
+		case "year":
+			t = now.AddDate(-n, 0, 0)
+		}
+		return t.Format(time.RFC3339), nil
+	}
+
+	return "", ErrFlagTimeOption
+}
+
+// parseTime validates an absolute time. Times without an offset are in UTC
+// and times with an offset are converted to UTC. Dates are kept as dates.
 func parseTime(timeStr string) (string, error) {
 
 	if strings.Count(timeStr, ":") == 2 && strings.Count(timeStr, "T") == 1 {
 		timeStr = timeStr + "Z"
 	}
 
-	timeFormats := []string{time.RFC3339, time.DateOnly}
+	if t, err := time.Parse(time.DateOnly, timeStr); err == nil {
+		return t.Format(time.DateOnly), nil
+	}
+
+	timeFormats := []string{time.RFC3339, "2006-01-02 15:04:05Z07:00", time.DateTime}
 	for _, tf := range timeFormats {
 		if t, err := time.Parse(tf, timeStr); err == nil {
-			return t.Format(tf), nil
+			return t.UTC().Format(time.RFC3339), nil
 		}
 	}
 	return "", ErrFlagTimeOption
@@ -265,6 +484,10 @@ func ExtractId(endCmd, formatFlag, id string) (string, error) {
 		v.Set("format", "text")
 	case "csv":
 		v.Set("format", "csv")
+	case "quakeml":
+		fallthrough
+	case "xml":
+		v.Set("format", "xml")
 	default:
 		return "", ErrFlagFormatOption
 	}
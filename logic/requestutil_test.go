@@ -1,6 +1,9 @@
 package logic
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 type MagnitudeTest struct {
 	in, outBegin, outEnd string
@@ -55,8 +58,27 @@ func TestExtractTime(t *testing.T) {
 		{",", "", "", nil},
 		{"", "","", nil},
 		{"2024-12-01-2024-12-02", "", "", ErrFlagTimeOption},
+		{"2024-12-01,", "2024-12-01", "", nil},
+		{",2024-12-02", "", "2024-12-02", nil},
+		{"2024-12-01T09:00:00+09:00,2024-12-01T12:00:00-08:00", "2024-12-01T00:00:00Z", "2024-12-01T20:00:00Z", nil},
+		{"-7d", "2024-06-08T12:00:00Z", "", nil},
+		{" -36h ", "2024-06-14T00:00:00Z", "", nil},
+		{"-90m", "2024-06-15T10:30:00Z", "", nil},
+		{"-2w,-1w", "2024-06-01T12:00:00Z", "2024-06-08T12:00:00Z", nil},
+		{"last 2 weeks", "2024-06-01T12:00:00Z", "", nil},
+		{"Last  Month", "2024-05-15T12:00:00Z", "", nil},
+		{"last 3 days,", "2024-06-12T12:00:00Z", "", nil},
+		{"2024-12-01", "", "", ErrFlagTimeOption},
+		{"-7x", "", "", ErrFlagTimeOption},
+		{"last fortnight", "", "", ErrFlagTimeOption},
+		{"2024-12-01,2024-12-02,2024-12-03", "", "", ErrFlagTimeOption},
 	}
 
+	timeNow = func() time.Time {
+		return time.Date(2024, time.June, 15, 21, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	}
+	defer func() { timeNow = time.Now }()
+
 	for _, test := range timeTests {
 		begin, end, err := extractTime(test.in)
 		if begin != test.outBegin || end != test.outEnd || err != test.err {
@@ -72,6 +94,9 @@ func TestParseTime(t *testing.T) {
 		{"-12-01", "", ErrFlagTimeOption},
 		{"20-12-01", "", ErrFlagTimeOption},
 		{"2024-12-01T", "", ErrFlagTimeOption},
+		{"2024-12-01T12:34:56+05:30", "2024-12-01T07:04:56Z", nil},
+		{"2024-12-01 12:34:56", "2024-12-01T12:34:56Z", nil},
+		{"2024-12-01 12:34:56-07:00", "2024-12-01T19:34:56Z", nil},
 	}
 
 	for _, test := range tTests {
@@ -100,3 +125,74 @@ func TestValidateId(t *testing.T) {
 		}
 	}
 }
+
+type RangeTest struct {
+	in, outBegin, outEnd string
+	err                  error
+}
+
+func TestExtractRange(t *testing.T) {
+	rTests := []RangeTest{
+		{"0,70", "0", "70", nil},
+		{" -2 , 10 ", "-2", "10", nil},
+		{">300", "300", "", nil},
+		{"< 10", "", "10", nil},
+		{"33", "33", "33", nil},
+		{"-1.5", "-1.5", "-1.5", nil},
+		{"0,", "0", "", nil},
+		{"", "", "", nil},
+		{",", "", "", ErrFlagDepthOption},
+		{"0,70,100", "", "", ErrFlagDepthOption},
+		{"0-70", "", "", ErrFlagDepthOption},
+		{">deep", "", "", ErrFlagDepthOption},
+	}
+
+	for _, test := range rTests {
+		begin, end, err := extractRange(test.in, ErrFlagDepthOption)
+		if begin != test.outBegin || end != test.outEnd || err != test.err {
+			t.Errorf("extractRange(%q) = %v %v %v; want %v %v %v", test.in, begin, end, err, test.outBegin, test.outEnd, test.err)
+		}
+	}
+}
+
+type FDSNParamsTest struct {
+	in  FDSNFlags
+	out string
+	err error
+}
+
+func TestExtractFDSNParams(t *testing.T) {
+	pTests := []FDSNParamsTest{
+		{FDSNFlags{Format: "table"}, FDSNENDPOINT + "/query?format=geojson", nil},
+		{FDSNFlags{Format: "csv", Mag: ">4.5"}, FDSNENDPOINT + "/query?format=csv&minmagnitude=4.5", nil},
+		{FDSNFlags{Format: "quakeml"}, FDSNENDPOINT + "/query?format=xml", nil},
+		{FDSNFlags{Format: "xml"}, FDSNENDPOINT + "/query?format=xml", nil},
+		{FDSNFlags{Format: "kml"}, FDSNENDPOINT + "/query?format=geojson", nil},
+		{FDSNFlags{Format: "ndjson"}, FDSNENDPOINT + "/query?format=geojson", nil},
+		{FDSNFlags{Format: "json", OrderBy: "magnitude", Limit: 100, Offset: 201}, FDSNENDPOINT + "/query?format=geojson&limit=100&offset=201&orderby=magnitude", nil},
+		{FDSNFlags{Format: "json", FilterFlags: FilterFlags{Depth: "<10", BBox: "-60,-10,170,-170"}},
+			FDSNENDPOINT + "/query?format=geojson&maxdepth=10&maxlatitude=-10&maxlongitude=190&minlatitude=-60&minlongitude=170", nil},
+		{FDSNFlags{Format: "json", FilterFlags: FilterFlags{BBox: "32,42,-125,-114", Radius: "37.8,-122.4,150km"}}, "", ErrFlagRegionOption},
+		{FDSNFlags{Format: "json", FilterFlags: FilterFlags{EventType: "earthquake", ReviewStatus: "reviewed", AlertLevel: "orange"}},
+			FDSNENDPOINT + "/query?alertlevel=orange&eventtype=earthquake&format=geojson&reviewstatus=reviewed", nil},
+		{FDSNFlags{Format: "json", FilterFlags: FilterFlags{ReviewStatus: "reviewed,deleted", AlertLevel: "orange,red"}},
+			FDSNENDPOINT + "/query?format=geojson&includedeleted=true", nil},
+		{FDSNFlags{Format: "json", FilterFlags: FilterFlags{Felt: "10,100", Cdi: ">4.5", Mmi: "<7", Sig: "100,600", Tsunami: true}},
+			FDSNENDPOINT + "/query?format=geojson&maxmmi=7&maxsig=600&mincdi=4.5&minfelt=10&minsig=100", nil},
+		{FDSNFlags{Format: "json", Catalog: "us", Contributor: " ci "}, FDSNENDPOINT + "/query?catalog=us&contributor=ci&format=geojson", nil},
+		{FDSNFlags{Format: "json", Catalog: "us,ak"}, "", ErrFlagCatalogOption},
+		{FDSNFlags{Format: "json", Updated: "2024-06-01T08:30:00"}, FDSNENDPOINT + "/query?format=geojson&updatedafter=2024-06-01T08%3A30%3A00Z", nil},
+		{FDSNFlags{Format: "json", Updated: "yesterday"}, "", ErrFlagUpdatedOption},
+		{FDSNFlags{Format: "json", OrderBy: "depth"}, "", ErrFlagOrderOption},
+		{FDSNFlags{Format: "json", Limit: 20001}, "", ErrFlagLimitOption},
+		{FDSNFlags{Format: "json", Offset: -1}, "", ErrFlagOffsetOption},
+		{FDSNFlags{Format: "yaml"}, "", ErrFlagFormatOption},
+	}
+
+	for _, test := range pTests {
+		endpoint, err := ExtractFDSNParams("query", test.in)
+		if endpoint != test.out || err != test.err {
+			t.Errorf("ExtractFDSNParams(%+v) = %v %v; want %v %v", test.in, endpoint, err, test.out, test.err)
+		}
+	}
+}
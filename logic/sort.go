@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+)
+
+var ErrFlagOrderOption = errors.New("--order-by option invalid")
+
+// orderings maps the FDSN orderby values onto comparisons of two Features.
+var orderings = map[string]func(a, b Feature) int{
+	"time": func(a, b Feature) int {
+		return cmp.Compare(b.Props.Time, a.Props.Time)
+	},
+	"time-asc": func(a, b Feature) int {
+		return cmp.Compare(a.Props.Time, b.Props.Time)
+	},
+	"magnitude": func(a, b Feature) int {
+		return cmp.Compare(sortMagnitude(b), sortMagnitude(a))
+	},
+	"magnitude-asc": func(a, b Feature) int {
+		return cmp.Compare(sortMagnitude(a), sortMagnitude(b))
+	},
+}
+
+// sortMagnitude returns the magnitude of an event, placing events without one
+// below every magnitude.
+func sortMagnitude(f Feature) float64 {
+	if f.Props.Mag == nil {
+		return math.Inf(-1)
+	}
+	return *f.Props.Mag
+}
+
+// ValidateOrder checks an order-by flag value against the FDSN orderby
+// values. An empty value leaves the order to the server.
+func ValidateOrder(orderFlag string) error {
+	if len(orderFlag) == 0 {
+		return nil
+	}
+	if _, hasKey := orderings[orderFlag]; !hasKey {
+		return ErrFlagOrderOption
+	}
+	return nil
+}
+
+// SortFeatures orders Features locally in the same way the FDSN service
+// orders a response. Features that compare equal keep their original order.
+func SortFeatures(features Features, orderFlag string) error {
+	if err := ValidateOrder(orderFlag); err != nil {
+		return err
+	}
+	if len(orderFlag) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(features, orderings[orderFlag])
+	return nil
+}
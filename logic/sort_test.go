@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"slices"
+	"testing"
+)
+
+type SortTest struct {
+	in  string
+	out []string
+	err error
+}
+
+func TestSortFeatures(t *testing.T) {
+	features := Features{
+		{Id: "a", Props: Properties{Time: 200, Mag: ptr(4.5)}},
+		{Id: "b", Props: Properties{Time: 300, Mag: ptr(2.1)}},
+		{Id: "c", Props: Properties{Time: 100, Mag: ptr(4.5)}},
+		{Id: "d", Props: Properties{Time: 400, Mag: ptr(6.0)}},
+	}
+
+	sTests := []SortTest{
+		{"", []string{"a", "b", "c", "d"}, nil},
+		{"time", []string{"d", "b", "a", "c"}, nil},
+		{"time-asc", []string{"c", "a", "b", "d"}, nil},
+		{"magnitude", []string{"d", "a", "c", "b"}, nil},
+		{"magnitude-asc", []string{"b", "a", "c", "d"}, nil},
+		{"depth", []string{"a", "b", "c", "d"}, ErrFlagOrderOption},
+	}
+
+	for _, test := range sTests {
+		sorted := slices.Clone(features)
+		err := SortFeatures(sorted, test.in)
+
+		var ids []string
+		for _, f := range sorted {
+			ids = append(ids, f.Id)
+		}
+		if !slices.Equal(ids, test.out) || err != test.err {
+			t.Errorf("SortFeatures(%q) = %v %v; want %v %v", test.in, ids, err, test.out, test.err)
+		}
+	}
+}
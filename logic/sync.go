@@ -0,0 +1,115 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SyncState remembers the high-water mark of each synced query: the latest
+// update time, in Unix milliseconds, of the events retrieved by the last
+// successful run. Queries are keyed by their request URL, see SyncKey.
+type SyncState struct {
+	Marks map[string]int64 `json:"marks"`
+}
+
+// DefaultSyncStatePath returns the location of the sync state file within the
+// user's configuration directory, e.g. ~/.config/geteq/sync.json on Linux.
+func DefaultSyncStatePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "geteq", "sync.json")
+}
+
+// LoadSyncState reads the sync state file at path. A missing file is not an
+// error and returns an empty SyncState.
+func LoadSyncState(path string) (*SyncState, error) {
+	state := &SyncState{Marks: make(map[string]int64)}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, err
+	}
+	if state.Marks == nil {
+		state.Marks = make(map[string]int64)
+	}
+	return state, nil
+}
+
+// Save writes the sync state to path, replacing the previous file only once
+// the new state has been written in full.
+func (s *SyncState) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SyncKey returns the key a query's high-water mark is stored under. Flags
+// that only shape the response, rather than which events match, are left out.
+// The time range is kept as given rather than resolved, so that a range
+// relative to now, such as "-7d", keys the same query on every run.
+func SyncKey(flags FDSNFlags) (string, error) {
+	if _, _, err := extractTime(flags.DateTime); err != nil {
+		return "", err
+	}
+	timeFlag := strings.TrimSpace(flags.DateTime)
+
+	flags.Format = "json"
+	flags.DateTime = ""
+	flags.Updated = ""
+	flags.OrderBy = ""
+	flags.Limit = 0
+	flags.Offset = 0
+	endpoint, err := ExtractFDSNParams("query", flags)
+	if err != nil || len(timeFlag) == 0 {
+		return endpoint, err
+	}
+
+	key, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	v := key.Query()
+	v.Set("time", timeFlag)
+	key.RawQuery = v.Encode()
+	return key.String(), nil
+}
+
+// FormatMark converts a high-water mark into an --updated-after flag value.
+func FormatMark(mark int64) string {
+	return time.UnixMilli(mark).UTC().Format(time.RFC3339)
+}
+
+// HighWaterMark returns the latest update time among the Features, or mark
+// when none of them were updated later.
+func HighWaterMark(features Features, mark int64) int64 {
+	for _, f := range features {
+		mark = max(mark, f.Props.Updated)
+	}
+	return mark
+}
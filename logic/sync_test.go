@@ -0,0 +1,75 @@
+package logic
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSyncStateSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geteq", "sync.json")
+
+	state, err := LoadSyncState(path)
+	if err != nil || len(state.Marks) != 0 {
+		t.Fatalf("LoadSyncState(missing) = %v %v; want empty state", state, err)
+	}
+
+	state.Marks["query"] = 1718000000000
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	loaded, err := LoadSyncState(path)
+	if err != nil || loaded.Marks["query"] != 1718000000000 {
+		t.Errorf("LoadSyncState() = %v %v; want mark 1718000000000", loaded, err)
+	}
+}
+
+func TestHighWaterMark(t *testing.T) {
+	features := Features{
+		{Props: Properties{Updated: 300}},
+		{Props: Properties{Updated: 500}},
+		{Props: Properties{Updated: 100}},
+	}
+
+	if mark := HighWaterMark(features, 200); mark != 500 {
+		t.Errorf("HighWaterMark(features, 200) = %d; want 500", mark)
+	}
+	if mark := HighWaterMark(nil, 200); mark != 200 {
+		t.Errorf("HighWaterMark(nil, 200) = %d; want 200", mark)
+	}
+	if mark := FormatMark(1718000000123); mark != "2024-06-10T06:13:20Z" {
+		t.Errorf("FormatMark(1718000000123) = %s; want 2024-06-10T06:13:20Z", mark)
+	}
+}
+
+func TestSyncKey(t *testing.T) {
+	a, errA := SyncKey(FDSNFlags{Format: "table", Mag: ">4.5", Updated: "2024-01-01", Limit: 10})
+	b, errB := SyncKey(FDSNFlags{Format: "json", Mag: ">4.5", OrderBy: "time-asc"})
+	if a != b || errA != nil || errB != nil {
+		t.Errorf("SyncKey() = %q %v, %q %v; want equal keys", a, errA, b, errB)
+	}
+}
+
+func TestSyncKeyRelativeTime(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+
+	flags := FDSNFlags{Mag: ">4.5", DateTime: "-7d"}
+	timeNow = func() time.Time { return time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC) }
+	a, errA := SyncKey(flags)
+	timeNow = func() time.Time { return time.Date(2024, 6, 16, 8, 30, 0, 0, time.UTC) }
+	b, errB := SyncKey(flags)
+	if a != b || errA != nil || errB != nil {
+		t.Errorf("SyncKey(%q) = %q %v, %q %v; want equal keys", flags.DateTime, a, errA, b, errB)
+	}
+
+	flags.DateTime = "last 2 weeks"
+	if c, err := SyncKey(flags); c == a || err != nil {
+		t.Errorf("SyncKey(%q) = %q %v; want a key other than %q", flags.DateTime, c, err, a)
+	}
+
+	flags.DateTime = "yesterday"
+	if _, err := SyncKey(flags); err != ErrFlagTimeOption {
+		t.Errorf("SyncKey(%q) = %v; want %v", flags.DateTime, err, ErrFlagTimeOption)
+	}
+}
@@ -0,0 +1,100 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+const WATCHMAXBACKOFF = 10 * time.Minute
+
+// Watcher remembers the update time of every event it has reported so that
+// only new or updated events are reported again.
+type Watcher struct {
+	seen map[string]int64
+}
+
+func NewWatcher() *Watcher {
+	return &Watcher{seen: make(map[string]int64)}
+}
+
+// Diff returns the Features that are new or were updated since the previous
+// call, oldest first. Events that dropped out of the feed are forgotten.
+func (w *Watcher) Diff(features Features) Features {
+	diff := make(Features, 0)
+	current := make(map[string]int64, len(features))
+	for _, f := range features {
+		current[f.Id] = f.Props.Updated
+		if updated, hasKey := w.seen[f.Id]; !hasKey || updated != f.Props.Updated {
+			diff = append(diff, f)
+		}
+	}
+	w.seen = current
+
+	SortFeatures(diff, "time-asc")
+	return diff
+}
+
+// WatchFeed returns the summary feed time range to poll at an interval. The
+// feed covers at least two intervals so that events are not missed between
+// polls.
+func WatchFeed(interval time.Duration) (string, error) {
+	if interval <= 0 {
+		return "", ErrFlagIntervalOption
+	}
+	for _, t := range rtTimeFeeds {
+		if 2*interval <= t.length {
+			return t.feed, nil
+		}
+	}
+	return "", ErrFlagIntervalOption
+}
+
+// Watch polls the GeoJSON feed at endpoint every interval until ctx is done,
+// passing the new or updated Features after filtering to handle. The Features
+// of the first successful poll are the events already in the feed rather than
+// new ones, which handle is told by initial. Failed requests are reported to
+// errLog and retried after a back off that doubles up to WATCHMAXBACKOFF.
+// Watch returns nil once ctx is done, or the first error returned by handle.
+func Watch(ctx context.Context, endpoint string, interval time.Duration, filters []Filter, handle func(features Features, initial bool) error, errLog io.Writer) error {
+	w := NewWatcher()
+	backoff := interval
+	initial := true
+
+	for {
+		wait := interval
+		features, err := requestFeatures(ctx, endpoint)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			fmt.Fprintf(errLog, "Error: %v (retrying in %v)\n", err, backoff)
+			wait = backoff
+			backoff = min(2*backoff, WATCHMAXBACKOFF)
+		default:
+			backoff = interval
+			diff := FilterFeatures(w.Diff(features), filters...)
+			if len(diff) != 0 {
+				if err := handle(diff, initial); err != nil {
+					return err
+				}
+			}
+			initial = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+func requestFeatures(ctx context.Context, endpoint string) (Features, error) {
+	content, err := RequestContentContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractFeatures(content)
+}
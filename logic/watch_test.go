@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type WatchFeedTest struct {
+	in  time.Duration
+	out string
+	err error
+}
+
+func featureIds(features Features) []string {
+	var ids []string
+	for _, f := range features {
+		ids = append(ids, f.Id)
+	}
+	return ids
+}
+
+func TestWatcherDiff(t *testing.T) {
+	w := NewWatcher()
+
+	first := Features{
+		{Id: "b", Props: Properties{Time: 200, Updated: 200}},
+		{Id: "a", Props: Properties{Time: 100, Updated: 100}},
+	}
+	if ids := featureIds(w.Diff(first)); !slices.Equal(ids, []string{"a", "b"}) {
+		t.Errorf("Diff(first) = %v; want [a b]", ids)
+	}
+
+	if ids := featureIds(w.Diff(first)); ids != nil {
+		t.Errorf("Diff(first) again = %v; want []", ids)
+	}
+
+	second := Features{
+		{Id: "c", Props: Properties{Time: 300, Updated: 300}},
+		{Id: "b", Props: Properties{Time: 200, Updated: 250}},
+		{Id: "a", Props: Properties{Time: 100, Updated: 100}},
+	}
+	if ids := featureIds(w.Diff(second)); !slices.Equal(ids, []string{"b", "c"}) {
+		t.Errorf("Diff(second) = %v; want [b c]", ids)
+	}
+}
+
+func TestWatchFeed(t *testing.T) {
+	wTests := []WatchFeedTest{
+		{time.Minute, "hour", nil},
+		{30 * time.Minute, "hour", nil},
+		{time.Hour, "day", nil},
+		{12 * time.Hour, "day", nil},
+		{3 * 24 * time.Hour, "week", nil},
+		{20 * 24 * time.Hour, "", ErrFlagIntervalOption},
+		{0, "", ErrFlagIntervalOption},
+	}
+
+	for _, test := range wTests {
+		feed, err := WatchFeed(test.in)
+		if feed != test.out || err != test.err {
+			t.Errorf("WatchFeed(%v) = %v %v; want %v %v", test.in, feed, err, test.out, test.err)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	feeds := []string{
+		`{"features": [{"id": "a", "properties": {"time": 100, "updated": 100}}]}`,
+		`{"features": [{"id": "b", "properties": {"time": 200, "updated": 200}}, {"id": "a", "properties": {"time": 100, "updated": 100}}]}`,
+	}
+
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		// Fail the first request to exercise the back off.
+		if requests == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, feeds[min(requests-2, len(feeds)-1)])
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var batches [][]string
+	var initials []bool
+	handle := func(features Features, initial bool) error {
+		batches = append(batches, featureIds(features))
+		initials = append(initials, initial)
+		if len(batches) == 2 {
+			cancel()
+		}
+		return nil
+	}
+
+	if err := Watch(ctx, server.URL, 10*time.Millisecond, nil, handle, io.Discard); err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+
+	if len(batches) != 2 || !slices.Equal(batches[0], []string{"a"}) || !slices.Equal(batches[1], []string{"b"}) {
+		t.Errorf("Watch() batches = %v; want [[a] [b]]", batches)
+	}
+	if !slices.Equal(initials, []bool{true, false}) {
+		t.Errorf("Watch() initial = %v; want [true false]", initials)
+	}
+}